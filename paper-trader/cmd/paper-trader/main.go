@@ -0,0 +1,138 @@
+// Command paper-trader is a small operator CLI that sits alongside the
+// server binary. Today it has one subcommand, replay, which drives
+// internal/backtest against a directory of tick corpora and fails CI on
+// any divergence from a checked-in golden results file.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/yourorg/paper-trader/internal/backtest"
+	"github.com/yourorg/paper-trader/internal/domain"
+	"github.com/yourorg/paper-trader/internal/execution"
+	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
+	"github.com/yourorg/paper-trader/internal/reserve"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: paper-trader <replay> [flags]")
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "replay":
+		if err := runReplay(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "replay:", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "unknown subcommand:", os.Args[1])
+		os.Exit(1)
+	}
+}
+
+// noOpStrategy never trades; replay's golden-file check is about the
+// engine reproducing the same equity curve from the same corpus, not about
+// any particular trading strategy.
+func noOpStrategy(ctx context.Context, tick domain.PriceTick, view backtest.PortfolioView) []domain.Order {
+	return nil
+}
+
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	corporaDir := fs.String("corpora-dir", "", "directory of tick corpora (.csv or .ndjson) to replay")
+	goldenDir := fs.String("golden-dir", "", "directory of checked-in golden Report JSON, one per corpus file")
+	portfolioIDStr := fs.String("portfolio-id", "", "portfolio to replay orders against")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *corporaDir == "" || *goldenDir == "" || *portfolioIDStr == "" {
+		return fmt.Errorf("-corpora-dir, -golden-dir, and -portfolio-id are all required")
+	}
+
+	_ = godotenv.Load()
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	portfolioID, err := uuid.Parse(*portfolioIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid -portfolio-id: %w", err)
+	}
+
+	db, err := pgRepo.Connect(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+
+	portfolioRepo := pgRepo.NewPortfolioRepo(db)
+	positionRepo := pgRepo.NewPositionRepo(db)
+	orderRepo := pgRepo.NewOrderRepo(db)
+	ledgerRepo := pgRepo.NewLedgerRepo(db)
+	idempotencyRepo := pgRepo.NewIdempotencyRepo(db)
+	instrumentRepo := pgRepo.NewInstrumentRepo(db)
+	reserveMgr := reserve.NewManager(reserve.DefaultTTL)
+
+	entries, err := os.ReadDir(*corporaDir)
+	if err != nil {
+		return fmt.Errorf("read corpora dir: %w", err)
+	}
+
+	ctx := context.Background()
+	var diverged []string
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		corpusPath := filepath.Join(*corporaDir, entry.Name())
+		ticks, err := backtest.LoadCorpus(corpusPath)
+		if err != nil {
+			return fmt.Errorf("load corpus %s: %w", entry.Name(), err)
+		}
+		if len(ticks) == 0 {
+			continue
+		}
+
+		priceRepo := backtest.NewReplayPriceRepo()
+		clock := backtest.NewVirtualClock(ticks[0].Timestamp)
+		orderSvc := execution.NewOrderService(db, portfolioRepo, positionRepo, orderRepo, ledgerRepo, idempotencyRepo, instrumentRepo, priceRepo, reserveMgr)
+		orderSvc.SetClock(clock)
+		engine := backtest.NewEngine(orderSvc, priceRepo, clock, portfolioRepo, positionRepo, nil)
+
+		runID := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		report, err := engine.Run(ctx, runID, portfolioID, ticks, noOpStrategy)
+		if err != nil {
+			return fmt.Errorf("run corpus %s: %w", entry.Name(), err)
+		}
+
+		goldenPath := filepath.Join(*goldenDir, runID+".json")
+		golden, err := os.ReadFile(goldenPath)
+		if err != nil {
+			logger.Warn("no golden file found, skipping comparison", "corpus", entry.Name())
+			continue
+		}
+		var wantReport backtest.Report
+		if err := json.Unmarshal(golden, &wantReport); err != nil {
+			return fmt.Errorf("parse golden file %s: %w", goldenPath, err)
+		}
+		if !reflect.DeepEqual(*report, wantReport) {
+			diverged = append(diverged, runID)
+			logger.Error("run diverged from golden result", "corpus", entry.Name())
+		}
+	}
+
+	if len(diverged) > 0 {
+		return fmt.Errorf("%d run(s) diverged from golden results: %s", len(diverged), strings.Join(diverged, ", "))
+	}
+	return nil
+}
@@ -2,20 +2,24 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/yourorg/paper-trader/internal/auth"
+	"github.com/yourorg/paper-trader/internal/domain"
 	"github.com/yourorg/paper-trader/internal/execution"
 	"github.com/yourorg/paper-trader/internal/gateway"
 	"github.com/yourorg/paper-trader/internal/ingestion"
 	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
 	redisRepo "github.com/yourorg/paper-trader/internal/repository/redis"
+	"github.com/yourorg/paper-trader/internal/reserve"
 )
 
 func main() {
@@ -28,10 +32,15 @@ func main() {
 	alpacaKey := os.Getenv("ALPACA_API_KEY")
 	alpacaSecret := os.Getenv("ALPACA_API_SECRET")
 	jwtSecret := os.Getenv("JWT_SECRET")
+	jwtPreviousSecret := os.Getenv("JWT_SECRET_PREVIOUS")
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	mdProvider := os.Getenv("MARKETDATA_PROVIDER")
+	if mdProvider == "" {
+		mdProvider = "alpaca"
+	}
 
 	db, err := pgRepo.Connect(dbURL)
 	if err != nil {
@@ -58,27 +67,64 @@ func main() {
 	positionRepo := pgRepo.NewPositionRepo(db)
 	orderRepo := pgRepo.NewOrderRepo(db)
 	ledgerRepo := pgRepo.NewLedgerRepo(db)
+	idempotencyRepo := pgRepo.NewIdempotencyRepo(db)
+	instrumentRepo := pgRepo.NewInstrumentRepo(db)
+	transferRepo := pgRepo.NewTransferRepo(db)
+	refreshTokenRepo := pgRepo.NewRefreshTokenRepo(db)
+	instrumentCache := redisRepo.NewInstrumentCache(redisClient, instrumentRepo)
 	priceRepo := redisRepo.NewPriceRepo(redisClient)
+	reserveMgr := reserve.NewManager(reserve.DefaultTTL)
+	revocationStore := redisRepo.NewJTIRevocationStore(redisClient)
 
-	jwtSvc := auth.NewJWTService(jwtSecret)
+	jwtSvc := auth.NewJWTService(loadSigningKeys(jwtSecret, jwtPreviousSecret))
 
-	orderSvc := execution.NewOrderService(db, portfolioRepo, positionRepo, orderRepo, ledgerRepo, priceRepo)
+	orderSvc := execution.NewOrderService(db, portfolioRepo, positionRepo, orderRepo, ledgerRepo, idempotencyRepo, instrumentCache, priceRepo, reserveMgr)
+	transferSvc := execution.NewTransferService(db, portfolioRepo, transferRepo, ledgerRepo, reserveMgr, execution.DefaultSettlementDelay)
 
 	hub := gateway.NewHub(priceRepo, logger)
 
-	alpacaClient := ingestion.NewAlpacaClient(alpacaKey, alpacaSecret, priceRepo, logger)
+	tradableSymbols, err := instrumentRepo.ListTradable(context.Background())
+	if err != nil {
+		logger.Error("failed to list tradable instruments", "err", err)
+		os.Exit(1)
+	}
+	symbols := make([]string, len(tradableSymbols))
+	for i, sym := range tradableSymbols {
+		symbols[i] = sym.Symbol
+	}
+
+	if err := orderSvc.Rehydrate(context.Background()); err != nil {
+		logger.Error("failed to rehydrate resting orders", "err", err)
+		os.Exit(1)
+	}
+
+	reconcileLedgerAtStartup(context.Background(), portfolioRepo, ledgerRepo, logger)
+
+	mdManager := ingestion.NewManager(priceRepo, logger)
+	switch mdProvider {
+	case "synthetic":
+		mdManager.Register(ingestion.NewSyntheticProvider(100, 0.3, time.Second, 1, logger), symbols)
+	case "replay":
+		mdManager.Register(ingestion.NewReplayProvider(os.Getenv("MARKETDATA_REPLAY_FILE"), 1, logger), symbols)
+	default:
+		mdManager.Register(ingestion.NewAlpacaClient(alpacaKey, alpacaSecret, logger), symbols)
+	}
 
 	handlers := gateway.NewHandlers(
-		userRepo, portfolioRepo, positionRepo, orderRepo, ledgerRepo,
-		orderSvc, jwtSvc, logger,
+		userRepo, portfolioRepo, positionRepo, orderRepo, ledgerRepo, instrumentRepo, transferRepo, refreshTokenRepo,
+		orderSvc, transferSvc, reserveMgr, jwtSvc, revocationStore, logger,
 	)
-	router := gateway.NewRouter(handlers, hub, jwtSvc)
+	router := gateway.NewRouter(handlers, hub, jwtSvc, revocationStore)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	go hub.Run(ctx)
-	go alpacaClient.Run(ctx)
+	go mdManager.Run(ctx)
+	go reserveMgr.RunSweeper(ctx, 10*time.Second)
+	go runIdempotencySweeper(ctx, idempotencyRepo, logger)
+	go runMatchingProcessor(ctx, priceRepo, orderSvc, symbols, logger)
+	go transferSvc.RunSettlementWorker(ctx, time.Second, logger)
 
 	srv := &http.Server{
 		Addr:         ":" + port,
@@ -105,3 +151,99 @@ func main() {
 	}
 	logger.Info("server stopped")
 }
+
+// runMatchingProcessor subscribes to every tradable symbol's price channel
+// and drives OrderService.ProcessTick off of it, so resting limit, stop, and
+// stop-limit orders get matched as ticks arrive rather than only at
+// submission time. It blocks until ctx is cancelled and should be run in
+// its own goroutine.
+func runMatchingProcessor(ctx context.Context, priceRepo *redisRepo.PriceRepo, orderSvc *execution.OrderService, symbols []string, logger *slog.Logger) {
+	var wg sync.WaitGroup
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+			pumpMatching(ctx, priceRepo, orderSvc, symbol, logger)
+		}(symbol)
+	}
+	wg.Wait()
+}
+
+func pumpMatching(ctx context.Context, priceRepo *redisRepo.PriceRepo, orderSvc *execution.OrderService, symbol string, logger *slog.Logger) {
+	pubsub := priceRepo.Subscribe(ctx, symbol)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var tick domain.PriceTick
+			if err := json.Unmarshal([]byte(msg.Payload), &tick); err != nil {
+				logger.Error("failed to decode price tick", "symbol", symbol, "err", err)
+				continue
+			}
+			if err := orderSvc.ProcessTick(ctx, tick); err != nil {
+				logger.Error("failed to process tick", "symbol", symbol, "err", err)
+			}
+		}
+	}
+}
+
+// loadSigningKeys builds the JWTService keyset from env vars: the current
+// secret signs every new access token, kept active indefinitely since
+// rotation here is operator-driven rather than scheduled. An optional
+// previous secret stays valid only for verifying tokens issued before the
+// last rotation — once every such token would have expired anyway, the
+// operator drops JWT_SECRET_PREVIOUS and this key disappears from the set.
+func loadSigningKeys(current, previous string) []auth.SigningKey {
+	keys := []auth.SigningKey{{Kid: "current", Secret: current}}
+	if previous != "" {
+		keys = append(keys, auth.SigningKey{Kid: "previous", Secret: previous})
+	}
+	return keys
+}
+
+// reconcileLedgerAtStartup replays every portfolio's ledger postings and
+// logs any that fail ReconcileLedger's balance check. A corrupt ledger is
+// an operational alarm, not a reason to refuse to serve other portfolios,
+// so this only logs rather than exiting.
+func reconcileLedgerAtStartup(ctx context.Context, portfolioRepo *pgRepo.PortfolioRepo, ledgerRepo *pgRepo.LedgerRepo, logger *slog.Logger) {
+	ids, err := portfolioRepo.ListIDs(ctx)
+	if err != nil {
+		logger.Error("failed to list portfolios for ledger reconciliation", "err", err)
+		return
+	}
+	for _, id := range ids {
+		if err := ledgerRepo.ReconcileLedger(ctx, id); err != nil {
+			logger.Error("ledger reconciliation failed", "portfolio_id", id, "err", err)
+		}
+	}
+}
+
+// runIdempotencySweeper periodically reclaims idempotency records past
+// pgRepo.RecordTTL. It blocks until ctx is cancelled and should be run in
+// its own goroutine.
+func runIdempotencySweeper(ctx context.Context, repo *pgRepo.IdempotencyRepo, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := repo.DeleteExpired(ctx)
+			if err != nil {
+				logger.Error("failed to sweep expired idempotency records", "err", err)
+				continue
+			}
+			if n > 0 {
+				logger.Info("swept expired idempotency records", "count", n)
+			}
+		}
+	}
+}
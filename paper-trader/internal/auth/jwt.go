@@ -1,46 +1,144 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/yourorg/paper-trader/internal/domain"
 )
 
+// AccessTokenTTL is how long an access token is valid before a client must
+// redeem its refresh token for a new one.
+const AccessTokenTTL = 15 * time.Minute
+
+// SigningKey is one entry in JWTService's keyset, identified by Kid in the
+// JWT header. NotBefore and NotAfter bound when the key is eligible to sign
+// new tokens; a zero value is unbounded on that side. A key normally stays
+// in the set (NotAfter zero or in the future) well after a newer key takes
+// over signing, purely so Parse can still verify tokens issued before the
+// rotation.
+type SigningKey struct {
+	Kid       string
+	Secret    string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+func (k SigningKey) active(now time.Time) bool {
+	if !k.NotBefore.IsZero() && now.Before(k.NotBefore) {
+		return false
+	}
+	if !k.NotAfter.IsZero() && !now.Before(k.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// ErrUnknownSigningKey is returned by Parse when a token's kid header
+// doesn't match any key in the service's keyset.
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// JWTService signs and verifies access tokens against a keyset loaded at
+// boot, so a secret can be rotated by adding a new key ahead of its
+// NotBefore rather than invalidating every outstanding token at once.
 type JWTService struct {
-	secret []byte
+	keys map[string]SigningKey
+	// order holds kids in the order passed to NewJWTService. currentKey
+	// breaks NotBefore ties toward whichever key comes first here, so which
+	// secret signs a new token never depends on Go's randomized map
+	// iteration order — callers are expected to pass their preferred
+	// current key first.
+	order []string
 }
 
-func NewJWTService(secret string) *JWTService {
-	return &JWTService{secret: []byte(secret)}
+func NewJWTService(keys []SigningKey) *JWTService {
+	byKid := make(map[string]SigningKey, len(keys))
+	order := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if _, exists := byKid[k.Kid]; !exists {
+			order = append(order, k.Kid)
+		}
+		byKid[k.Kid] = k
+	}
+	return &JWTService{keys: byKid, order: order}
 }
 
+// Claims is carried by every access token. Jti identifies this specific
+// token for revocation; PortfolioID lets every handler scope to the
+// caller's portfolio without a path parameter; Role gates the admin-only
+// endpoints.
 type Claims struct {
-	UserID      uuid.UUID `json:"user_id"`
-	PortfolioID uuid.UUID `json:"portfolio_id"`
+	UserID      uuid.UUID       `json:"user_id"`
+	PortfolioID uuid.UUID       `json:"portfolio_id"`
+	Role        domain.UserRole `json:"role"`
+	Jti         uuid.UUID       `json:"jti"`
 	jwt.RegisteredClaims
 }
 
-func (s *JWTService) Sign(userID, portfolioID uuid.UUID) (string, error) {
+// Sign picks the newest active signing key and issues a short-lived access
+// token stamped with that key's kid in the header, so Parse knows which
+// key to verify it against.
+func (s *JWTService) Sign(userID, portfolioID uuid.UUID, role domain.UserRole) (string, error) {
+	key, err := s.currentKey()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
 	claims := Claims{
 		UserID:      userID,
 		PortfolioID: portfolioID,
+		Role:        role,
+		Jti:         uuid.New(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	token.Header["kid"] = key.Kid
+	return token.SignedString([]byte(key.Secret))
 }
 
+// currentKey returns the active key with the latest NotBefore, i.e. the one
+// most recently rotated in. Two keys with equal NotBefore (commonly both
+// left at the zero value, since rotation here is operator-driven rather
+// than scheduled) tie-break toward whichever comes first in s.order.
+func (s *JWTService) currentKey() (SigningKey, error) {
+	var best SigningKey
+	found := false
+	now := time.Now()
+	for _, kid := range s.order {
+		k := s.keys[kid]
+		if !k.active(now) {
+			continue
+		}
+		if !found || k.NotBefore.After(best.NotBefore) {
+			best = k
+			found = true
+		}
+	}
+	if !found {
+		return SigningKey{}, fmt.Errorf("no active signing key")
+	}
+	return best, nil
+}
+
+// Parse verifies tokenStr against the key named by its kid header,
+// rejecting tokens signed by a key the service doesn't know about.
 func (s *JWTService) Parse(tokenStr string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return s.secret, nil
+		kid, _ := t.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, ErrUnknownSigningKey
+		}
+		return []byte(key.Secret), nil
 	})
 	if err != nil {
 		return nil, err
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/yourorg/paper-trader/internal/domain"
 )
 
 type contextKey string
@@ -13,9 +14,16 @@ type contextKey string
 const (
 	contextKeyUserID      contextKey = "userID"
 	contextKeyPortfolioID contextKey = "portfolioID"
+	contextKeyClaims      contextKey = "claims"
 )
 
-func Middleware(jwtSvc *JWTService) func(http.Handler) http.Handler {
+// RevocationStore is the subset of redis.JTIRevocationStore that Middleware
+// needs to reject access tokens logged out before their natural expiry.
+type RevocationStore interface {
+	IsRevoked(ctx context.Context, jti uuid.UUID) (bool, error)
+}
+
+func Middleware(jwtSvc *JWTService, revocationStore RevocationStore) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			header := r.Header.Get("Authorization")
@@ -29,8 +37,14 @@ func Middleware(jwtSvc *JWTService) func(http.Handler) http.Handler {
 				http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
 				return
 			}
+			revoked, err := revocationStore.IsRevoked(r.Context(), claims.Jti)
+			if err != nil || revoked {
+				http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
 			ctx := context.WithValue(r.Context(), contextKeyUserID, claims.UserID)
 			ctx = context.WithValue(ctx, contextKeyPortfolioID, claims.PortfolioID)
+			ctx = context.WithValue(ctx, contextKeyClaims, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -45,3 +59,26 @@ func PortfolioIDFromCtx(ctx context.Context) uuid.UUID {
 	v, _ := ctx.Value(contextKeyPortfolioID).(uuid.UUID)
 	return v
 }
+
+// ClaimsFromCtx returns the access token claims Middleware verified for
+// this request, for handlers (like Logout) that need the token's own Jti or
+// expiry rather than just the identity it carries.
+func ClaimsFromCtx(ctx context.Context) *Claims {
+	v, _ := ctx.Value(contextKeyClaims).(*Claims)
+	return v
+}
+
+// RequireAdmin rejects a request whose claims don't carry domain.RoleAdmin.
+// It must be chained after Middleware, which is what populates the claims
+// this reads; routes that act on shared state other portfolios depend on
+// (instrument metadata, forced ledger reconciliation) should sit behind it.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := ClaimsFromCtx(r.Context())
+		if claims == nil || claims.Role != domain.RoleAdmin {
+			http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
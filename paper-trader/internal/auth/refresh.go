@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RefreshTokenTTL bounds how long a refresh token can be redeemed before its
+// owner has to log in again.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// NewRefreshToken generates an opaque, high-entropy refresh token and
+// returns it alongside its hash. Only the hash is ever persisted, so a
+// leaked database dump can't be replayed as a live session.
+func NewRefreshToken() (token, tokenHash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken hashes a refresh token the same way on issuance and on
+// redemption, so redemption never has to store or compare the raw value.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+package backtest
+
+import (
+	"sync"
+	"time"
+)
+
+// VirtualClock is an execution.Clock driven by Engine instead of wall time,
+// so a replay run against the same corpus always produces the same session
+// hours decisions and fill timestamps.
+type VirtualClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Set advances the clock to t. Engine calls this once per tick, in corpus
+// order, before invoking the strategy.
+func (c *VirtualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
@@ -0,0 +1,101 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// LoadCorpus reads a tick corpus from path and returns it ordered by
+// Timestamp. CSV corpora are expected to have a header row
+// "symbol,price,size,timestamp"; NDJSON corpora are one domain.PriceTick
+// per line. The format is chosen by file extension (.csv vs .json/.ndjson).
+func LoadCorpus(path string) ([]domain.PriceTick, error) {
+	var ticks []domain.PriceTick
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		ticks, err = loadCSVCorpus(path)
+	default:
+		ticks, err = loadNDJSONCorpus(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i].Timestamp.Before(ticks[j].Timestamp) })
+	return ticks, nil
+}
+
+func loadCSVCorpus(path string) ([]domain.PriceTick, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open corpus: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read corpus: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ticks := make([]domain.PriceTick, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		if len(row) < 4 {
+			return nil, fmt.Errorf("malformed corpus row: %v", row)
+		}
+		price, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price: %w", err)
+		}
+		size, err := strconv.ParseFloat(row[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse size: %w", err)
+		}
+		ts, err := time.Parse(time.RFC3339Nano, row[3])
+		if err != nil {
+			return nil, fmt.Errorf("parse timestamp: %w", err)
+		}
+		ticks = append(ticks, domain.PriceTick{Symbol: row[0], Price: price, Size: size, Timestamp: ts})
+	}
+	return ticks, nil
+}
+
+func loadNDJSONCorpus(path string) ([]domain.PriceTick, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open corpus: %w", err)
+	}
+	defer f.Close()
+
+	var ticks []domain.PriceTick
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var tick domain.PriceTick
+		if err := json.Unmarshal(line, &tick); err != nil {
+			return nil, fmt.Errorf("parse corpus line: %w", err)
+		}
+		ticks = append(ticks, tick)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read corpus: %w", err)
+	}
+	return ticks, nil
+}
@@ -0,0 +1,180 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/paper-trader/internal/domain"
+	"github.com/yourorg/paper-trader/internal/execution"
+	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
+)
+
+// PortfolioView is the read-only snapshot of a portfolio a strategy sees
+// when deciding what to trade on a tick.
+type PortfolioView struct {
+	CashBalance float64
+	Positions   []domain.Position
+}
+
+// StrategyFunc decides what orders, if any, to submit in response to tick.
+type StrategyFunc func(ctx context.Context, tick domain.PriceTick, view PortfolioView) []domain.Order
+
+// Engine drives an unmodified execution.OrderService against a historical
+// tick corpus instead of a live market, advancing a VirtualClock and a
+// ReplayPriceRepo tick-by-tick so a run is fully reproducible.
+type Engine struct {
+	orderSvc      *execution.OrderService
+	priceRepo     *ReplayPriceRepo
+	clock         *VirtualClock
+	portfolioRepo *pgRepo.PortfolioRepo
+	positionRepo  *pgRepo.PositionRepo
+	snapshotRepo  *pgRepo.PnLSnapshotRepo
+}
+
+// NewEngine wires an Engine to replay against portfolioRepo/positionRepo's
+// database. snapshotRepo is optional: pass nil to skip persisting the
+// equity curve and rely on the returned Report alone.
+func NewEngine(
+	orderSvc *execution.OrderService,
+	priceRepo *ReplayPriceRepo,
+	clock *VirtualClock,
+	portfolioRepo *pgRepo.PortfolioRepo,
+	positionRepo *pgRepo.PositionRepo,
+	snapshotRepo *pgRepo.PnLSnapshotRepo,
+) *Engine {
+	return &Engine{
+		orderSvc:      orderSvc,
+		priceRepo:     priceRepo,
+		clock:         clock,
+		portfolioRepo: portfolioRepo,
+		positionRepo:  positionRepo,
+		snapshotRepo:  snapshotRepo,
+	}
+}
+
+// Run replays ticks in order against portfolioID, invoking strategy after
+// each tick's price is recorded and submitting whatever orders it returns.
+// runID labels the returned Report and, when the Engine has a snapshotRepo,
+// keys every equity-curve snapshot Run persists; the Report itself is not
+// persisted.
+func (e *Engine) Run(ctx context.Context, runID string, portfolioID uuid.UUID, ticks []domain.PriceTick, strategy StrategyFunc) (*Report, error) {
+	report := &Report{RunID: runID, PortfolioID: portfolioID.String()}
+
+	startingEquity, err := e.equity(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("compute starting equity: %w", err)
+	}
+	report.StartingEquity = startingEquity
+
+	for _, tick := range ticks {
+		e.clock.Set(tick.Timestamp)
+		e.priceRepo.SetPrice(tick)
+
+		// Match this tick against resting limit, stop, and stop-limit orders
+		// before the strategy sees the resulting portfolio, the same order
+		// production runs in via pumpMatching — otherwise an order a strategy
+		// submits because it didn't fill immediately would rest in memory for
+		// the rest of the run and never fill, unlike live trading.
+		if err := e.orderSvc.ProcessTick(ctx, tick); err != nil {
+			return nil, fmt.Errorf("process tick: %w", err)
+		}
+
+		view, err := e.portfolioView(ctx, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("build portfolio view: %w", err)
+		}
+
+		for _, order := range strategy(ctx, tick, view) {
+			order.PortfolioID = portfolioID
+			filled, err := e.orderSvc.SubmitAndExecute(ctx, uuid.New().String(), order)
+			if err != nil {
+				return nil, fmt.Errorf("submit order: %w", err)
+			}
+			if filled.Status == domain.StatusFilled && filled.FillPrice != nil {
+				report.Turnover += *filled.FillPrice * filled.FilledQty
+			}
+		}
+
+		equity, err := e.equity(ctx, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("compute equity: %w", err)
+		}
+		unrealized, err := e.unrealizedPnL(ctx, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("compute unrealized pnl: %w", err)
+		}
+		snapshot := PnLSnapshot{
+			Timestamp:     tick.Timestamp,
+			Equity:        equity,
+			UnrealizedPnL: unrealized,
+			RealizedPnL:   (equity - startingEquity) - unrealized,
+		}
+		report.Snapshots = append(report.Snapshots, snapshot)
+
+		if e.snapshotRepo != nil {
+			if err := e.snapshotRepo.Insert(ctx, runID, portfolioID, snapshot.Timestamp,
+				snapshot.Equity, snapshot.RealizedPnL, snapshot.UnrealizedPnL); err != nil {
+				return nil, fmt.Errorf("persist pnl snapshot: %w", err)
+			}
+		}
+	}
+
+	report.EndingEquity = report.StartingEquity
+	if n := len(report.Snapshots); n > 0 {
+		report.EndingEquity = report.Snapshots[n-1].Equity
+	}
+	report.MaxDrawdown = maxDrawdown(report.Snapshots)
+
+	return report, nil
+}
+
+func (e *Engine) portfolioView(ctx context.Context, portfolioID uuid.UUID) (PortfolioView, error) {
+	portfolio, err := e.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return PortfolioView{}, err
+	}
+	positions, err := e.positionRepo.GetByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return PortfolioView{}, err
+	}
+	return PortfolioView{CashBalance: portfolio.CashBalance, Positions: positions}, nil
+}
+
+// equity returns cash plus every position valued at its last replayed
+// price; positions in symbols the corpus hasn't ticked yet value at 0.
+func (e *Engine) equity(ctx context.Context, portfolioID uuid.UUID) (float64, error) {
+	view, err := e.portfolioView(ctx, portfolioID)
+	if err != nil {
+		return 0, err
+	}
+	equity := view.CashBalance
+	for _, pos := range view.Positions {
+		tick, err := e.priceRepo.GetLastPrice(ctx, pos.Symbol)
+		if err != nil {
+			return 0, err
+		}
+		if tick != nil {
+			equity += pos.Quantity * tick.Price
+		}
+	}
+	return equity, nil
+}
+
+func (e *Engine) unrealizedPnL(ctx context.Context, portfolioID uuid.UUID) (float64, error) {
+	view, err := e.portfolioView(ctx, portfolioID)
+	if err != nil {
+		return 0, err
+	}
+	var pnl float64
+	for _, pos := range view.Positions {
+		tick, err := e.priceRepo.GetLastPrice(ctx, pos.Symbol)
+		if err != nil {
+			return 0, err
+		}
+		if tick != nil {
+			pnl += pos.Quantity * (tick.Price - pos.AvgCost)
+		}
+	}
+	return pnl, nil
+}
@@ -0,0 +1,38 @@
+package backtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// ReplayPriceRepo is an execution.PriceSource fed entirely by Engine as it
+// advances through a tick corpus, so OrderService sees exactly the prices
+// the corpus specifies rather than whatever Redis happens to hold.
+type ReplayPriceRepo struct {
+	mu   sync.RWMutex
+	last map[string]domain.PriceTick
+}
+
+func NewReplayPriceRepo() *ReplayPriceRepo {
+	return &ReplayPriceRepo{last: make(map[string]domain.PriceTick)}
+}
+
+// SetPrice records tick as the latest price for its symbol. Called by
+// Engine before invoking the strategy and OrderService for each tick.
+func (r *ReplayPriceRepo) SetPrice(tick domain.PriceTick) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last[tick.Symbol] = tick
+}
+
+func (r *ReplayPriceRepo) GetLastPrice(ctx context.Context, symbol string) (*domain.PriceTick, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tick, ok := r.last[symbol]
+	if !ok {
+		return nil, nil
+	}
+	return &tick, nil
+}
@@ -0,0 +1,47 @@
+package backtest
+
+import "time"
+
+// PnLSnapshot is one point in a run's equity curve, taken after processing
+// a tick.
+type PnLSnapshot struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Equity        float64   `json:"equity"`
+	RealizedPnL   float64   `json:"realized_pnl"`
+	UnrealizedPnL float64   `json:"unrealized_pnl"`
+}
+
+// Report summarizes one Engine.Run. Snapshots is ordered by Timestamp and
+// is what a golden-file diff in the replay CLI compares run-to-run.
+type Report struct {
+	RunID          string        `json:"run_id"`
+	PortfolioID    string        `json:"portfolio_id"`
+	StartingEquity float64       `json:"starting_equity"`
+	EndingEquity   float64       `json:"ending_equity"`
+	MaxDrawdown    float64       `json:"max_drawdown"`
+	Turnover       float64       `json:"turnover"`
+	Snapshots      []PnLSnapshot `json:"snapshots"`
+}
+
+// maxDrawdown returns the largest peak-to-trough decline in equity across
+// snapshots, expressed as a positive fraction of the peak.
+func maxDrawdown(snapshots []PnLSnapshot) float64 {
+	if len(snapshots) == 0 {
+		return 0
+	}
+	peak := snapshots[0].Equity
+	var worst float64
+	for _, s := range snapshots {
+		if s.Equity > peak {
+			peak = s.Equity
+		}
+		if peak <= 0 {
+			continue
+		}
+		drawdown := (peak - s.Equity) / peak
+		if drawdown > worst {
+			worst = drawdown
+		}
+	}
+	return worst
+}
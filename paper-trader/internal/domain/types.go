@@ -32,20 +32,51 @@ const (
 	StatusRejected        OrderStatus = "rejected"
 )
 
-type EntryType string
+// Account is a leg of the per-portfolio chart of accounts. Besides the
+// fixed accounts below, every held instrument gets an "equity:SYMBOL"
+// account (see EquityAccount).
+type Account string
 
 const (
-	EntryDeposit    EntryType = "deposit"
-	EntryWithdrawal EntryType = "withdrawal"
-	EntryTradeBuy   EntryType = "trade_buy"
-	EntryTradeSell  EntryType = "trade_sell"
-	EntryFee        EntryType = "fee"
+	AccountCash        Account = "cash"
+	AccountPnLRealized Account = "pnl:realized"
+	AccountFees        Account = "fees"
+	AccountDividends   Account = "dividends"
+	// AccountExternal is the counterparty leg for cash moving to or from
+	// outside the system: a deposit credits AccountCash and debits
+	// AccountExternal, a withdrawal does the reverse.
+	AccountExternal Account = "external"
+	// AccountSuspense catches postings (e.g. migrated legacy rows) that
+	// can't be routed to a more specific account. A nonzero suspense
+	// balance is a reconciliation bug, not a valid steady state.
+	AccountSuspense Account = "suspense"
+)
+
+// EquityAccount is the per-symbol account for trades in symbol. A buy posts
+// its cost to it and a sell posts its proceeds out of it, so its balance is
+// the position's cost only until something is sold; no realized-PnL leg is
+// split out of a sell.
+func EquityAccount(symbol string) Account {
+	return Account("equity:" + symbol)
+}
+
+// UserRole gates access to the admin-only endpoints (instrument upserts,
+// forced ledger reconciliation) that act on shared state every portfolio's
+// order validation depends on, rather than on the caller's own portfolio.
+// There is no self-service way to become an admin — the role is set
+// directly in the database by an operator.
+type UserRole string
+
+const (
+	RoleTrader UserRole = "trader"
+	RoleAdmin  UserRole = "admin"
 )
 
 type User struct {
 	ID           uuid.UUID `db:"id"            json:"id"`
 	Email        string    `db:"email"         json:"email"`
 	PasswordHash string    `db:"password_hash" json:"-"`
+	Role         UserRole  `db:"role"          json:"role"`
 	CreatedAt    time.Time `db:"created_at"    json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"    json:"updated_at"`
 }
@@ -77,6 +108,7 @@ type Order struct {
 	OrderType    OrderType   `db:"order_type"    json:"order_type"`
 	Quantity     float64     `db:"quantity"      json:"quantity"`
 	LimitPrice   *float64    `db:"limit_price"   json:"limit_price,omitempty"`
+	StopPrice    *float64    `db:"stop_price"    json:"stop_price,omitempty"`
 	FillPrice    *float64    `db:"fill_price"    json:"fill_price,omitempty"`
 	FilledQty    float64     `db:"filled_qty"    json:"filled_qty"`
 	Status       OrderStatus `db:"status"        json:"status"`
@@ -86,14 +118,61 @@ type Order struct {
 	UpdatedAt    time.Time   `db:"updated_at"    json:"updated_at"`
 }
 
-type LedgerEntry struct {
-	ID           int64      `db:"id"            json:"id"`
-	PortfolioID  uuid.UUID  `db:"portfolio_id"  json:"portfolio_id"`
-	OrderID      *uuid.UUID `db:"order_id"      json:"order_id,omitempty"`
-	EntryType    EntryType  `db:"entry_type"    json:"entry_type"`
-	Amount       float64    `db:"amount"        json:"amount"`
-	BalanceAfter float64    `db:"balance_after" json:"balance_after"`
-	CreatedAt    time.Time  `db:"created_at"    json:"created_at"`
+type TransferKind string
+
+const (
+	TransferDeposit    TransferKind = "deposit"
+	TransferWithdrawal TransferKind = "withdrawal"
+)
+
+type TransferStatus string
+
+const (
+	TransferPending TransferStatus = "pending"
+	TransferSettled TransferStatus = "settled"
+	TransferFailed  TransferStatus = "failed"
+)
+
+// Transfer is a deposit into or withdrawal from a portfolio's cash balance.
+// It settles asynchronously — Status starts at TransferPending and the
+// settlement worker moves it to TransferSettled (posting the matching
+// Transaction) or TransferFailed after a configurable delay, simulating the
+// latency of a real ACH transfer.
+type Transfer struct {
+	ID          uuid.UUID      `db:"id"           json:"id"`
+	PortfolioID uuid.UUID      `db:"portfolio_id" json:"portfolio_id"`
+	Kind        TransferKind   `db:"kind"         json:"kind"`
+	Amount      float64        `db:"amount"       json:"amount"`
+	Status      TransferStatus `db:"status"       json:"status"`
+	Reference   string         `db:"reference"    json:"reference,omitempty"`
+	SettledAt   *time.Time     `db:"settled_at"   json:"settled_at,omitempty"`
+	CreatedAt   time.Time      `db:"created_at"   json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"   json:"updated_at"`
+}
+
+// Transaction groups one or more Postings that must move together — a
+// trade, a deposit, a fee assessment. Its Postings always sum to zero.
+type Transaction struct {
+	ID          uuid.UUID  `db:"id"           json:"id"`
+	PortfolioID uuid.UUID  `db:"portfolio_id" json:"portfolio_id"`
+	OrderID     *uuid.UUID `db:"order_id"     json:"order_id,omitempty"`
+	TransferID  *uuid.UUID `db:"transfer_id"  json:"transfer_id,omitempty"`
+	Memo        string     `db:"memo"         json:"memo"`
+	PostedAt    time.Time  `db:"posted_at"    json:"posted_at"`
+}
+
+// Posting is a single signed leg of a Transaction against one Account. It
+// carries the account's running balance as of this posting, so a
+// statement for an account never needs to replay history to show a
+// balance.
+type Posting struct {
+	ID             int64     `db:"id"              json:"id"`
+	TransactionID  uuid.UUID `db:"transaction_id"  json:"transaction_id"`
+	PortfolioID    uuid.UUID `db:"portfolio_id"    json:"portfolio_id"`
+	Account        Account   `db:"account"         json:"account"`
+	Amount         float64   `db:"amount"          json:"amount"`
+	RunningBalance float64   `db:"running_balance" json:"running_balance"`
+	CreatedAt      time.Time `db:"created_at"      json:"created_at"`
 }
 
 type PriceTick struct {
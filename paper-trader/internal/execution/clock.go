@@ -0,0 +1,21 @@
+package execution
+
+import "time"
+
+// Clock supplies the current time to OrderService. realClock is the
+// production implementation; backtest.Engine substitutes a virtual clock so
+// fills and timestamps replay deterministically against historical ticks.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// SetClock overrides the clock used for session-hours checks and fill
+// timestamps. Production callers never need this; NewOrderService already
+// defaults to the real wall clock.
+func (s *OrderService) SetClock(c Clock) {
+	s.clock = c
+}
@@ -0,0 +1,38 @@
+package conformance
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// FakePriceRepo is an in-memory execution.PriceSource. It lets a vector
+// replay its tick sequence deterministically, without a live Redis feed
+// racing the order submissions it's supposed to precede.
+type FakePriceRepo struct {
+	mu   sync.Mutex
+	last map[string]domain.PriceTick
+}
+
+func NewFakePriceRepo() *FakePriceRepo {
+	return &FakePriceRepo{last: make(map[string]domain.PriceTick)}
+}
+
+// Feed records tick as the last traded price for its symbol, as the real
+// Alpaca feed would on receiving a trade message.
+func (f *FakePriceRepo) Feed(tick domain.PriceTick) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.last[tick.Symbol] = tick
+}
+
+func (f *FakePriceRepo) GetLastPrice(ctx context.Context, symbol string) (*domain.PriceTick, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tick, ok := f.last[symbol]
+	if !ok {
+		return nil, nil
+	}
+	return &tick, nil
+}
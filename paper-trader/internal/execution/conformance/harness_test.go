@@ -0,0 +1,264 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/yourorg/paper-trader/internal/domain"
+	"github.com/yourorg/paper-trader/internal/execution"
+	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
+	"github.com/yourorg/paper-trader/internal/reserve"
+)
+
+// update runs every vector and overwrites it with the observed result
+// instead of asserting, so a refactor's effect on behavior shows up as a
+// diff to testdata/vectors/*.json in code review rather than a test name.
+var update = flag.Bool("update", false, "record observed results into the vectors instead of checking them")
+
+const vectorsDir = "testdata/vectors"
+
+// TestConformance replays every vector in testdata/vectors against a real
+// OrderService backed by Postgres (migrations applied from ../../../migrations)
+// and a FakePriceRepo standing in for Redis. It needs a throwaway database
+// with the project's base schema already applied; point TEST_DATABASE_URL
+// at one (e.g. a testcontainers-managed Postgres in CI) to run it. Without
+// that variable the suite is skipped rather than faked, since there's
+// nothing meaningful to assert against an in-memory stand-in for Postgres
+// here: the whole point is pinning down behavior that depends on real
+// transactional semantics (row locks, commits, rollbacks).
+func TestConformance(t *testing.T) {
+	dsn := testDatabaseURL(t)
+	db, err := pgRepo.Connect(dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+	if err := pgRepo.RunMigrations(dsn, "../../../migrations"); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(vectorsDir, "*.json"))
+	if err != nil {
+		t.Fatalf("glob vectors: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no vectors found under %s", vectorsDir)
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			runVector(t, db, path)
+		})
+	}
+}
+
+func runVector(t *testing.T, db *sqlx.DB, path string) {
+	t.Helper()
+	vec, err := LoadVector(path)
+	if err != nil {
+		t.Fatalf("load vector: %v", err)
+	}
+
+	portfolioID := seedPortfolio(t, db, vec.Initial)
+
+	priceRepo := NewFakePriceRepo()
+	portfolioRepo := pgRepo.NewPortfolioRepo(db)
+	positionRepo := pgRepo.NewPositionRepo(db)
+	orderRepo := pgRepo.NewOrderRepo(db)
+	ledgerRepo := pgRepo.NewLedgerRepo(db)
+	idempotencyRepo := pgRepo.NewIdempotencyRepo(db)
+	instrumentRepo := pgRepo.NewInstrumentRepo(db)
+	reserveMgr := reserve.NewManager(reserve.DefaultTTL)
+
+	svc := execution.NewOrderService(db, portfolioRepo, positionRepo, orderRepo, ledgerRepo,
+		idempotencyRepo, instrumentRepo, priceRepo, reserveMgr)
+
+	ctx := context.Background()
+	for _, ev := range vec.Events {
+		switch {
+		case ev.Tick != nil:
+			priceRepo.Feed(*ev.Tick)
+			if err := svc.ProcessTick(ctx, *ev.Tick); err != nil {
+				t.Fatalf("process tick: %v", err)
+			}
+		case ev.Order != nil:
+			step := ev.Order
+			req := step.Request
+			req.PortfolioID = portfolioID
+			result, err := svc.SubmitAndExecute(ctx, step.IdempotencyKey, req)
+			if *update {
+				step.Expected = result
+				if err != nil {
+					step.ExpectError = err.Error()
+				}
+				continue
+			}
+			if step.ExpectError != "" {
+				if err == nil || err.Error() != step.ExpectError {
+					t.Fatalf("order %s: expected error %q, got %v", step.IdempotencyKey, step.ExpectError, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("order %s: unexpected error: %v", step.IdempotencyKey, err)
+			}
+			if !ordersEqual(result, step.Expected) {
+				gotJSON, _ := json.MarshalIndent(normalizeOrder(result), "", "  ")
+				wantJSON, _ := json.MarshalIndent(normalizeOrder(step.Expected), "", "  ")
+				t.Fatalf("order %s: result mismatch\n got:  %s\nwant: %s", step.IdempotencyKey, gotJSON, wantJSON)
+			}
+		default:
+			t.Fatalf("event has neither tick nor order")
+		}
+	}
+
+	actual := collectState(t, ctx, portfolioRepo, positionRepo, ledgerRepo, portfolioID)
+	if *update {
+		vec.Expected = actual
+		if err := SaveVector(path, vec); err != nil {
+			t.Fatalf("write updated vector: %v", err)
+		}
+		return
+	}
+	if !reflect.DeepEqual(actual, vec.Expected) {
+		gotJSON, _ := json.MarshalIndent(actual, "", "  ")
+		wantJSON, _ := json.MarshalIndent(vec.Expected, "", "  ")
+		t.Fatalf("final state mismatch for %s\n got:  %s\nwant: %s", path, gotJSON, wantJSON)
+	}
+}
+
+// seedPortfolio creates a fresh user and portfolio for the vector to run
+// against, upserts its instrument catalog, and seeds any starting
+// positions. Every vector gets its own portfolio so runs never collide.
+func seedPortfolio(t *testing.T, db *sqlx.DB, initial InitialState) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	userRepo := pgRepo.NewUserRepo(db)
+	user := &domain.User{Email: uuid.NewString() + "@conformance.test", PasswordHash: "n/a"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	portfolioRepo := pgRepo.NewPortfolioRepo(db)
+	portfolio := &domain.Portfolio{UserID: user.ID, Name: "conformance", CashBalance: initial.CashBalance}
+	if err := portfolioRepo.Create(ctx, portfolio); err != nil {
+		t.Fatalf("seed portfolio: %v", err)
+	}
+
+	instrumentRepo := pgRepo.NewInstrumentRepo(db)
+	for i := range initial.Instruments {
+		sym := initial.Instruments[i]
+		if err := instrumentRepo.Upsert(ctx, &sym); err != nil {
+			t.Fatalf("seed instrument %s: %v", sym.Symbol, err)
+		}
+	}
+
+	if len(initial.Positions) > 0 {
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			t.Fatalf("begin seed tx: %v", err)
+		}
+		positionRepo := pgRepo.NewPositionRepo(db)
+		for _, pos := range initial.Positions {
+			if err := positionRepo.UpsertTx(ctx, tx, portfolio.ID, pos.Symbol, pos.Quantity, pos.AvgCost); err != nil {
+				tx.Rollback()
+				t.Fatalf("seed position %s: %v", pos.Symbol, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("commit seed tx: %v", err)
+		}
+	}
+
+	return portfolio.ID
+}
+
+func collectState(t *testing.T, ctx context.Context, portfolioRepo *pgRepo.PortfolioRepo, positionRepo *pgRepo.PositionRepo, ledgerRepo *pgRepo.LedgerRepo, portfolioID uuid.UUID) ExpectedState {
+	t.Helper()
+	portfolio, err := portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		t.Fatalf("read back portfolio: %v", err)
+	}
+	positions, err := positionRepo.GetByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		t.Fatalf("read back positions: %v", err)
+	}
+	txns, err := ledgerRepo.GetTransactionsByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		t.Fatalf("read back transactions: %v", err)
+	}
+	for i := range positions {
+		positions[i] = normalizePosition(positions[i])
+	}
+	for i := range txns {
+		txns[i] = normalizeTransaction(txns[i])
+	}
+	return ExpectedState{
+		CashBalance:  portfolio.CashBalance,
+		Positions:    positions,
+		Transactions: txns,
+	}
+}
+
+// ordersEqual compares two orders ignoring fields that are never
+// deterministic across runs (IDs, timestamps).
+func ordersEqual(got, want *domain.Order) bool {
+	return reflect.DeepEqual(normalizeOrder(got), normalizeOrder(want))
+}
+
+func normalizeOrder(o *domain.Order) domain.Order {
+	if o == nil {
+		return domain.Order{}
+	}
+	n := *o
+	n.ID = uuid.Nil
+	n.PortfolioID = uuid.Nil
+	n.CreatedAt = time.Time{}
+	n.UpdatedAt = time.Time{}
+	n.FilledAt = nil
+	return n
+}
+
+func normalizePosition(p domain.Position) domain.Position {
+	p.ID = uuid.Nil
+	p.PortfolioID = uuid.Nil
+	p.CreatedAt = time.Time{}
+	p.UpdatedAt = time.Time{}
+	return p
+}
+
+func normalizeTransaction(txn pgRepo.TransactionWithPostings) pgRepo.TransactionWithPostings {
+	txn.ID = uuid.Nil
+	txn.PortfolioID = uuid.Nil
+	txn.OrderID = nil
+	txn.PostedAt = time.Time{}
+	postings := make([]domain.Posting, len(txn.Postings))
+	for i, p := range txn.Postings {
+		p.ID = 0
+		p.TransactionID = uuid.Nil
+		p.PortfolioID = uuid.Nil
+		p.CreatedAt = time.Time{}
+		postings[i] = p
+	}
+	txn.Postings = postings
+	return txn
+}
+
+func testDatabaseURL(t *testing.T) string {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping conformance corpus (see TestConformance doc)")
+	}
+	return dsn
+}
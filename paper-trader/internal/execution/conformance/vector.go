@@ -0,0 +1,98 @@
+// Package conformance replays versioned scenario files ("vectors") against
+// a real OrderService to pin down its behavior across refactors of the
+// matching logic, the same way protocol implementations use a shared
+// corpus of interoperable test vectors to guarantee deterministic
+// behavior. See the package's test file for the runner.
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+	"github.com/yourorg/paper-trader/internal/instrument"
+	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
+)
+
+// CurrentVectorVersion is the schema version this package knows how to
+// run. Bump it whenever Vector's shape changes in a way that would make an
+// old vector silently pass (or silently fail) under the new schema, and
+// update every file under testdata/vectors accordingly.
+const CurrentVectorVersion = 1
+
+// Vector is a self-contained scenario: starting state, a deterministic
+// sequence of price ticks and order submissions, and the state the run
+// must produce.
+type Vector struct {
+	Version  int           `json:"version"`
+	Name     string        `json:"name"`
+	Initial  InitialState  `json:"initial"`
+	Events   []Event       `json:"events"`
+	Expected ExpectedState `json:"expected"`
+}
+
+// InitialState seeds the portfolio and instrument catalog before Events
+// replay.
+type InitialState struct {
+	CashBalance float64             `json:"cash_balance"`
+	Positions   []domain.Position   `json:"positions,omitempty"`
+	Instruments []instrument.Symbol `json:"instruments"`
+}
+
+// Event is one step of the replay: exactly one of Tick or Order is set.
+// Ordering within Events is the order they're replayed in, so a tick that
+// must be visible to a later order submission is listed before it.
+type Event struct {
+	Tick  *domain.PriceTick `json:"tick,omitempty"`
+	Order *OrderStep        `json:"order,omitempty"`
+}
+
+// OrderStep submits Request and checks the result: either ExpectError
+// matches SubmitAndExecute's error exactly, or Expected matches the
+// returned order.
+type OrderStep struct {
+	IdempotencyKey string        `json:"idempotency_key"`
+	Request        domain.Order  `json:"request"`
+	ExpectError    string        `json:"expect_error,omitempty"`
+	Expected       *domain.Order `json:"expected,omitempty"`
+}
+
+// ExpectedState is the portfolio's state after every Event has replayed.
+type ExpectedState struct {
+	CashBalance  float64                          `json:"cash_balance"`
+	Positions    []domain.Position                `json:"positions,omitempty"`
+	Transactions []pgRepo.TransactionWithPostings `json:"transactions,omitempty"`
+}
+
+// LoadVector reads and validates the vector at path. It refuses files
+// whose Version isn't CurrentVectorVersion rather than guessing at how to
+// interpret an unfamiliar schema.
+func LoadVector(path string) (*Vector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vector %s: %w", path, err)
+	}
+	var vec Vector
+	if err := json.Unmarshal(data, &vec); err != nil {
+		return nil, fmt.Errorf("parse vector %s: %w", path, err)
+	}
+	if vec.Version != CurrentVectorVersion {
+		return nil, fmt.Errorf("vector %s has version %d, runner supports %d", path, vec.Version, CurrentVectorVersion)
+	}
+	return &vec, nil
+}
+
+// SaveVector writes vec back to path with stable formatting, used by the
+// -update record mode so a regression shows up as a corpus diff in review.
+func SaveVector(path string, vec *Vector) error {
+	data, err := json.MarshalIndent(vec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal vector: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write vector %s: %w", path, err)
+	}
+	return nil
+}
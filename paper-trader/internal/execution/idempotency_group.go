@@ -0,0 +1,55 @@
+package execution
+
+import (
+	"sync"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// idempotencyGroup coalesces concurrent SubmitAndExecute calls sharing the
+// same (portfolio, key): only the first caller actually runs fn, and every
+// other caller attaches to that call and receives its result. This covers
+// the window a client's retry can land in before the first attempt has
+// committed its idempotency record to Postgres — without it, the retry
+// would queue on reserveMgr.Lock and then re-read that record from the
+// database; with it, the retry gets the first call's result (or error)
+// directly.
+type idempotencyGroup struct {
+	mu    sync.Mutex
+	calls map[string]*idempotencyCall
+}
+
+type idempotencyCall struct {
+	wg     sync.WaitGroup
+	result *domain.Order
+	err    error
+}
+
+func newIdempotencyGroup() *idempotencyGroup {
+	return &idempotencyGroup{calls: make(map[string]*idempotencyCall)}
+}
+
+// do runs fn for groupKey if no call for it is already in flight, or waits
+// for and returns the in-flight call's result otherwise.
+func (g *idempotencyGroup) do(groupKey string, fn func() (*domain.Order, error)) (*domain.Order, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[groupKey]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.result, call.err
+	}
+
+	call := &idempotencyCall{}
+	call.wg.Add(1)
+	g.calls[groupKey] = call
+	g.mu.Unlock()
+
+	call.result, call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, groupKey)
+	g.mu.Unlock()
+
+	return call.result, call.err
+}
@@ -0,0 +1,15 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/yourorg/paper-trader/internal/instrument"
+)
+
+// InstrumentSource supplies contract metadata for a symbol. pgRepo.InstrumentRepo
+// is the production implementation; redis.InstrumentCache wraps it with a
+// short-TTL cache so every order submission doesn't round-trip Postgres for
+// data that rarely changes.
+type InstrumentSource interface {
+	GetBySymbol(ctx context.Context, symbol string) (*instrument.Symbol, error)
+}
@@ -0,0 +1,184 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/paper-trader/internal/domain"
+	"github.com/yourorg/paper-trader/internal/matching"
+)
+
+// ProcessTick advances the matching engine by one price tick: any stop or
+// stop-limit order it triggers is converted into an immediate fill (a plain
+// stop) or a resting limit order (a stop-limit), then every fill the
+// resting book produces against tick is applied through the same
+// ledger/position/cash path a submitted order uses.
+func (s *OrderService) ProcessTick(ctx context.Context, tick domain.PriceTick) error {
+	for _, order := range s.matchingEngine.Triggered(tick) {
+		if order.OrderType == domain.TypeStopLimit {
+			s.matchingEngine.RestLimit(order)
+			continue
+		}
+		if err := s.fillTriggeredStop(ctx, order, tick.Price); err != nil {
+			return fmt.Errorf("fill triggered stop %s: %w", order.ID, err)
+		}
+	}
+
+	for _, fill := range s.matchingEngine.Match(tick) {
+		if err := s.applyRestingFill(ctx, fill); err != nil {
+			return fmt.Errorf("apply fill for order %s: %w", fill.Order.ID, err)
+		}
+	}
+	return nil
+}
+
+// fillTriggeredStop fills a plain stop order in full at price the instant it
+// triggers — a stop order becomes a market order once crossed. Its cash
+// reservation was only ever sized at submission time against StopPrice, not
+// the actual trigger price, so a buy stop is re-checked against available
+// cash here and rejected rather than filled if that cash no longer covers
+// the fill at the trigger price. A sell stop needs no such check: it only ever proceeds cash,
+// never spends it.
+func (s *OrderService) fillTriggeredStop(ctx context.Context, order *domain.Order, price float64) error {
+	unlock := s.reserveMgr.Lock(order.PortfolioID)
+	defer unlock()
+
+	if reservationID, ok := s.restingResvs.take(order.ID); ok {
+		s.reserveMgr.Release(order.PortfolioID, reservationID)
+	}
+	s.shareResvs.take(order.ID)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if order.Side == domain.SideBuy {
+		portfolio, err := s.portfolioRepo.GetByIDForUpdateTx(ctx, tx, order.PortfolioID)
+		if err != nil {
+			return fmt.Errorf("get portfolio: %w", err)
+		}
+		available := portfolio.CashBalance - s.reserveMgr.Reserved(order.PortfolioID)
+		if available < price*order.Quantity {
+			reason := "insufficient funds"
+			if err := s.orderRepo.UpdateRejectedTx(ctx, tx, order.ID, reason); err != nil {
+				return fmt.Errorf("reject triggered stop: %w", err)
+			}
+			return tx.Commit()
+		}
+	}
+
+	if err := s.applyFill(ctx, tx, order.PortfolioID, order.ID, order.Symbol, order.Side, price, order.Quantity); err != nil {
+		return err
+	}
+	if err := s.orderRepo.ApplyFillTx(ctx, tx, order.ID, price, order.Quantity); err != nil {
+		return fmt.Errorf("update order status: %w", err)
+	}
+	return tx.Commit()
+}
+
+// applyRestingFill records one match against a resting limit order. A
+// partial fill leaves the reservation in place; the reservation is released
+// only once the order's cumulative filled quantity (tracked in-memory on
+// fill.Order by the matching engine itself) reaches its full quantity.
+func (s *OrderService) applyRestingFill(ctx context.Context, fill matching.Fill) error {
+	order := fill.Order
+
+	unlock := s.reserveMgr.Lock(order.PortfolioID)
+	defer unlock()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.applyFill(ctx, tx, order.PortfolioID, order.ID, order.Symbol, order.Side, fill.Price, fill.Qty); err != nil {
+		return err
+	}
+	if err := s.orderRepo.ApplyFillTx(ctx, tx, order.ID, fill.Price, fill.Qty); err != nil {
+		return fmt.Errorf("update order status: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if order.FilledQty >= order.Quantity {
+		if reservationID, ok := s.restingResvs.take(order.ID); ok {
+			s.reserveMgr.Release(order.PortfolioID, reservationID)
+		}
+		s.shareResvs.take(order.ID)
+	}
+	return nil
+}
+
+// CancelOrder pulls a still-working order belonging to portfolioID out of
+// the matching engine and marks it cancelled, releasing any cash held
+// against it. It fails if the order isn't resting — already filled,
+// already cancelled, or a market order, which never rests.
+func (s *OrderService) CancelOrder(ctx context.Context, portfolioID, orderID uuid.UUID) error {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return ErrOrderNotFound
+	}
+	if order.PortfolioID != portfolioID {
+		return ErrOrderNotFound
+	}
+
+	unlock := s.reserveMgr.Lock(portfolioID)
+	defer unlock()
+
+	if !s.matchingEngine.Cancel(orderID) {
+		return ErrOrderNotResting
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.orderRepo.CancelTx(ctx, tx, orderID); err != nil {
+		return fmt.Errorf("cancel order: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if reservationID, ok := s.restingResvs.take(orderID); ok {
+		s.reserveMgr.Release(portfolioID, reservationID)
+	}
+	s.shareResvs.take(orderID)
+	return nil
+}
+
+// Rehydrate loads every still-working non-market order from Postgres and
+// re-populates the in-memory matching engine, so a server restart doesn't
+// silently drop resting orders. It does not recreate their cash or share
+// reservations — those are in-process only and were lost along with the
+// previous engine, so rehydrated buy orders go unfunded and rehydrated sell
+// orders go unheld until they fill or are cancelled. A stop-limit order
+// with a nonzero filled quantity must have
+// already triggered and be resting in the book; one with zero filled
+// quantity is assumed to still be waiting on its stop price, which is only
+// ever wrong if it triggered but hadn't matched anything yet when the
+// process stopped, in which case it simply waits for the next crossing
+// tick to trigger again.
+func (s *OrderService) Rehydrate(ctx context.Context) error {
+	orders, err := s.orderRepo.ListResting(ctx)
+	if err != nil {
+		return fmt.Errorf("list resting orders: %w", err)
+	}
+	for i := range orders {
+		o := &orders[i]
+		switch {
+		case o.OrderType == domain.TypeLimit, o.FilledQty > 0:
+			s.matchingEngine.RestLimit(o)
+		default:
+			s.matchingEngine.RestStop(o)
+		}
+	}
+	return nil
+}
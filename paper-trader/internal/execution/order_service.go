@@ -2,22 +2,51 @@ package execution
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/yourorg/paper-trader/internal/domain"
+	"github.com/yourorg/paper-trader/internal/matching"
 	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
-	redisRepo "github.com/yourorg/paper-trader/internal/repository/redis"
+	"github.com/yourorg/paper-trader/internal/reserve"
 )
 
+// ErrIdempotencyKeyReused is returned when a client replays an
+// Idempotency-Key with a request body that doesn't match the one originally
+// associated with it. Callers should surface this as HTTP 409.
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request body")
+
+// ErrOrderNotFound is returned by CancelOrder when orderID doesn't exist or
+// doesn't belong to the calling portfolio. Callers should surface this as
+// HTTP 404.
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrOrderNotResting is returned by CancelOrder when orderID exists but
+// isn't sitting in the matching engine — already filled, already
+// cancelled, or a market order, which never rests. Callers should surface
+// this as HTTP 409.
+var ErrOrderNotResting = errors.New("order is not resting")
+
 type OrderService struct {
-	db            *sqlx.DB
-	portfolioRepo *pgRepo.PortfolioRepo
-	positionRepo  *pgRepo.PositionRepo
-	orderRepo     *pgRepo.OrderRepo
-	ledgerRepo    *pgRepo.LedgerRepo
-	priceRepo     *redisRepo.PriceRepo
+	db              *sqlx.DB
+	portfolioRepo   *pgRepo.PortfolioRepo
+	positionRepo    *pgRepo.PositionRepo
+	orderRepo       *pgRepo.OrderRepo
+	ledgerRepo      *pgRepo.LedgerRepo
+	idempotencyRepo *pgRepo.IdempotencyRepo
+	instrumentRepo  InstrumentSource
+	priceRepo       PriceSource
+	reserveMgr      *reserve.Manager
+	idempotencyGrp  *idempotencyGroup
+	clock           Clock
+	matchingEngine  *matching.Engine
+	restingResvs    *reservationRegistry
+	shareResvs      *shareReservations
 }
 
 func NewOrderService(
@@ -26,23 +55,107 @@ func NewOrderService(
 	positionRepo *pgRepo.PositionRepo,
 	orderRepo *pgRepo.OrderRepo,
 	ledgerRepo *pgRepo.LedgerRepo,
-	priceRepo *redisRepo.PriceRepo,
+	idempotencyRepo *pgRepo.IdempotencyRepo,
+	instrumentRepo InstrumentSource,
+	priceRepo PriceSource,
+	reserveMgr *reserve.Manager,
 ) *OrderService {
 	return &OrderService{
-		db:            db,
-		portfolioRepo: portfolioRepo,
-		positionRepo:  positionRepo,
-		orderRepo:     orderRepo,
-		ledgerRepo:    ledgerRepo,
-		priceRepo:     priceRepo,
+		db:              db,
+		portfolioRepo:   portfolioRepo,
+		positionRepo:    positionRepo,
+		orderRepo:       orderRepo,
+		ledgerRepo:      ledgerRepo,
+		idempotencyRepo: idempotencyRepo,
+		instrumentRepo:  instrumentRepo,
+		priceRepo:       priceRepo,
+		reserveMgr:      reserveMgr,
+		idempotencyGrp:  newIdempotencyGroup(),
+		clock:           realClock{},
+		matchingEngine:  matching.NewEngine(),
+		restingResvs:    newReservationRegistry(),
+		shareResvs:      newShareReservations(),
 	}
 }
 
-func (s *OrderService) SubmitAndExecute(ctx context.Context, req domain.Order) (*domain.Order, error) {
+// canonicalRequestHash hashes the fields of req that define "the same
+// order", so a retried submission can be told apart from a client reusing a
+// stale Idempotency-Key for a different order.
+func canonicalRequestHash(req domain.Order) (string, error) {
+	canonical := struct {
+		PortfolioID string   `json:"portfolio_id"`
+		Symbol      string   `json:"symbol"`
+		Side        string   `json:"side"`
+		OrderType   string   `json:"order_type"`
+		Quantity    float64  `json:"quantity"`
+		LimitPrice  *float64 `json:"limit_price,omitempty"`
+		StopPrice   *float64 `json:"stop_price,omitempty"`
+	}{
+		PortfolioID: req.PortfolioID.String(),
+		Symbol:      req.Symbol,
+		Side:        string(req.Side),
+		OrderType:   string(req.OrderType),
+		Quantity:    req.Quantity,
+		LimitPrice:  req.LimitPrice,
+		StopPrice:   req.StopPrice,
+	}
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SubmitAndExecute validates, reserves cash for, and either fills req now or
+// rests it in the matching engine (a non-marketable limit, stop, or
+// stop-limit order). idempotencyKey
+// is required: a resubmission with the same key and an identical body
+// returns the original result, and a resubmission with the same key but a
+// different body fails with ErrIdempotencyKeyReused.
+func (s *OrderService) SubmitAndExecute(ctx context.Context, idempotencyKey string, req domain.Order) (*domain.Order, error) {
+	if idempotencyKey == "" {
+		return nil, fmt.Errorf("idempotency key is required")
+	}
+
+	groupKey := req.PortfolioID.String() + ":" + idempotencyKey
+	return s.idempotencyGrp.do(groupKey, func() (*domain.Order, error) {
+		return s.submitAndExecute(ctx, idempotencyKey, req)
+	})
+}
+
+// submitAndExecute is the body of SubmitAndExecute, run under
+// idempotencyGrp's coalescing so at most one attempt per (portfolio, key)
+// is ever in flight at once.
+func (s *OrderService) submitAndExecute(ctx context.Context, idempotencyKey string, req domain.Order) (*domain.Order, error) {
 	if err := validateOrderRequest(&req); err != nil {
 		return nil, err
 	}
 
+	requestHash, err := canonicalRequestHash(req)
+	if err != nil {
+		return nil, fmt.Errorf("hash request: %w", err)
+	}
+
+	// Serialize submissions for this portfolio so buying-power checks and
+	// cash reservations below see a consistent view of one another.
+	unlock := s.reserveMgr.Lock(req.PortfolioID)
+	defer unlock()
+
+	if existing, err := s.lookupIdempotent(ctx, req.PortfolioID, idempotencyKey, requestHash); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return existing, nil
+	}
+
+	sym, err := s.instrumentRepo.GetBySymbol(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("lookup instrument: %w", err)
+	}
+	if sym == nil {
+		return nil, fmt.Errorf("unknown symbol: %s", req.Symbol)
+	}
+
 	tick, err := s.priceRepo.GetLastPrice(ctx, req.Symbol)
 	if err != nil {
 		return nil, fmt.Errorf("price lookup failed: %w", err)
@@ -51,51 +164,86 @@ func (s *OrderService) SubmitAndExecute(ctx context.Context, req domain.Order) (
 		return nil, fmt.Errorf("no price data available for symbol: %s", req.Symbol)
 	}
 
-	fillPrice := tick.Price
-	cost := fillPrice * req.Quantity
-
-	req.Status = domain.StatusPending
-
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	if reason, err := validateAgainstInstrument(&req, sym, tick.Price); err != nil {
+		req.Status = domain.StatusRejected
+		req.RejectReason = &reason
+		return s.persistTerminal(ctx, tx, &req, idempotencyKey, requestHash)
+	}
+
+	if req.OrderType == domain.TypeMarket && !inSessionFor(sym, s.clock.Now()) {
+		// A market order has no resting representation in the matching
+		// engine — it either fills against the last price now or it
+		// doesn't exist. There's no "wait for the open" mechanism anywhere
+		// else in the book (ProcessTick, ListResting, CancelOrder all work
+		// off resting orders only), so rather than parking this in a status
+		// nothing will ever advance or let the client cancel, reject it
+		// outright. The client can resubmit once the session opens, or use
+		// a limit order to rest through the gap.
+		req.Status = domain.StatusRejected
+		reason := RejectReasonOffSession
+		req.RejectReason = &reason
+		return s.persistTerminal(ctx, tx, &req, idempotencyKey, requestHash)
+	}
+
+	fillNow := crossesImmediately(&req, tick.Price)
+	fillPrice := tick.Price
+
+	var cost float64
+	if fillNow {
+		cost = fillPrice * req.Quantity
+	} else {
+		cost = reservationPrice(&req, tick.Price) * req.Quantity
+	}
+
+	req.Status = domain.StatusPending
+
 	portfolio, err := s.portfolioRepo.GetByIDForUpdateTx(ctx, tx, req.PortfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("get portfolio: %w", err)
 	}
 
+	var reservationID uuid.UUID
+	var hasReservation bool
 	if req.Side == domain.SideBuy {
-		if portfolio.CashBalance < cost {
+		available := portfolio.CashBalance - s.reserveMgr.Reserved(req.PortfolioID)
+		if available < cost {
 			req.Status = domain.StatusRejected
 			reason := "insufficient funds"
 			req.RejectReason = &reason
-			if err := s.orderRepo.CreateTx(ctx, tx, &req); err != nil {
-				return nil, err
-			}
-			if err := tx.Commit(); err != nil {
-				return nil, err
-			}
-			return &req, nil
+			return s.persistTerminal(ctx, tx, &req, idempotencyKey, requestHash)
+		}
+		if fillNow {
+			reservationID = s.reserveMgr.Reserve(req.PortfolioID, cost)
+			hasReservation = true
+			defer func() {
+				if hasReservation {
+					s.reserveMgr.Release(req.PortfolioID, reservationID)
+				}
+			}()
+		} else {
+			reservationID = s.reserveMgr.ReserveIndefinite(req.PortfolioID, cost)
 		}
 	} else {
 		pos, err := s.positionRepo.GetBySymbolTx(ctx, tx, req.PortfolioID, req.Symbol)
 		if err != nil {
 			return nil, fmt.Errorf("get position: %w", err)
 		}
-		if pos == nil || pos.Quantity < req.Quantity {
+		var held float64
+		if pos != nil {
+			held = pos.Quantity
+		}
+		available := held - s.shareResvs.reserved(req.PortfolioID, req.Symbol)
+		if available < req.Quantity {
 			req.Status = domain.StatusRejected
 			reason := "insufficient position"
 			req.RejectReason = &reason
-			if err := s.orderRepo.CreateTx(ctx, tx, &req); err != nil {
-				return nil, err
-			}
-			if err := tx.Commit(); err != nil {
-				return nil, err
-			}
-			return &req, nil
+			return s.persistTerminal(ctx, tx, &req, idempotencyKey, requestHash)
 		}
 	}
 
@@ -103,65 +251,150 @@ func (s *OrderService) SubmitAndExecute(ctx context.Context, req domain.Order) (
 		return nil, fmt.Errorf("create order: %w", err)
 	}
 
+	if !fillNow {
+		// Marketable orders are handled above; anything left either rests
+		// in the book (a non-marketable limit) or waits in the stop set
+		// (stop and stop-limit) until a later tick crosses it.
+		if req.OrderType == domain.TypeLimit {
+			s.matchingEngine.RestLimit(&req)
+		} else {
+			s.matchingEngine.RestStop(&req)
+		}
+		if req.Side == domain.SideBuy {
+			s.restingResvs.put(req.ID, reservationID)
+		} else {
+			s.shareResvs.put(req.ID, req.PortfolioID, req.Symbol, req.Quantity)
+		}
+		if err := s.idempotencyRepo.InsertTx(ctx, tx, req.PortfolioID, idempotencyKey, req.ID, requestHash); err != nil {
+			return nil, fmt.Errorf("insert idempotency record: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit transaction: %w", err)
+		}
+		return &req, nil
+	}
+
+	if err := s.applyFill(ctx, tx, req.PortfolioID, req.ID, req.Symbol, req.Side, fillPrice, req.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderRepo.UpdateStatusTx(ctx, tx, req.ID, domain.StatusFilled, fillPrice, req.Quantity); err != nil {
+		return nil, fmt.Errorf("update order status: %w", err)
+	}
+
+	if err := s.idempotencyRepo.InsertTx(ctx, tx, req.PortfolioID, idempotencyKey, req.ID, requestHash); err != nil {
+		return nil, fmt.Errorf("insert idempotency record: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	// The reservation is now consumed by the committed fill; don't release it.
+	hasReservation = false
+
+	req.Status = domain.StatusFilled
+	req.FillPrice = &fillPrice
+	req.FilledQty = req.Quantity
+	now := s.clock.Now()
+	req.FilledAt = &now
+
+	return &req, nil
+}
+
+// applyFill moves cash and position by qty shares of symbol at price,
+// within tx, and posts the matching ledger transaction. It covers the
+// money-movement side of a fill only — callers are responsible for writing
+// the order row itself via orderRepo.CreateTx/UpdateStatusTx (an order
+// filling immediately at submission) or orderRepo.ApplyFillTx (a resting
+// order matched later by the matching engine).
+func (s *OrderService) applyFill(ctx context.Context, tx *sqlx.Tx, portfolioID, orderID uuid.UUID, symbol string, side domain.OrderSide, price, qty float64) error {
+	portfolio, err := s.portfolioRepo.GetByIDForUpdateTx(ctx, tx, portfolioID)
+	if err != nil {
+		return fmt.Errorf("get portfolio: %w", err)
+	}
+
+	cost := price * qty
 	var newBalance float64
-	var entryType domain.EntryType
-	if req.Side == domain.SideBuy {
+	var memo string
+	if side == domain.SideBuy {
 		newBalance = portfolio.CashBalance - cost
-		entryType = domain.EntryTradeBuy
-		if err := s.positionRepo.UpsertTx(ctx, tx, req.PortfolioID, req.Symbol, req.Quantity, fillPrice); err != nil {
-			return nil, fmt.Errorf("upsert position: %w", err)
+		memo = "trade_buy"
+		if err := s.positionRepo.UpsertTx(ctx, tx, portfolioID, symbol, qty, price); err != nil {
+			return fmt.Errorf("upsert position: %w", err)
 		}
 	} else {
 		newBalance = portfolio.CashBalance + cost
-		entryType = domain.EntryTradeSell
-		pos, err := s.positionRepo.GetBySymbolTx(ctx, tx, req.PortfolioID, req.Symbol)
+		memo = "trade_sell"
+		pos, err := s.positionRepo.GetBySymbolTx(ctx, tx, portfolioID, symbol)
 		if err != nil {
-			return nil, fmt.Errorf("get position for sell: %w", err)
+			return fmt.Errorf("get position for sell: %w", err)
+		}
+		if pos == nil {
+			return fmt.Errorf("sell fill for %s with no position on portfolio %s", symbol, portfolioID)
 		}
-		newQty := pos.Quantity - req.Quantity
+		newQty := pos.Quantity - qty
 		if newQty == 0 {
-			if err := s.positionRepo.DeleteTx(ctx, tx, req.PortfolioID, req.Symbol); err != nil {
-				return nil, fmt.Errorf("delete position: %w", err)
+			if err := s.positionRepo.DeleteTx(ctx, tx, portfolioID, symbol); err != nil {
+				return fmt.Errorf("delete position: %w", err)
 			}
 		} else {
-			if err := s.positionRepo.UpdateQtyTx(ctx, tx, req.PortfolioID, req.Symbol, newQty); err != nil {
-				return nil, fmt.Errorf("update position qty: %w", err)
+			if err := s.positionRepo.UpdateQtyTx(ctx, tx, portfolioID, symbol, newQty); err != nil {
+				return fmt.Errorf("update position qty: %w", err)
 			}
 		}
 	}
 
-	if err := s.portfolioRepo.UpdateCashBalanceTx(ctx, tx, req.PortfolioID, newBalance); err != nil {
-		return nil, fmt.Errorf("update cash balance: %w", err)
+	if err := s.portfolioRepo.UpdateCashBalanceTx(ctx, tx, portfolioID, newBalance); err != nil {
+		return fmt.Errorf("update cash balance: %w", err)
 	}
 
-	if err := s.orderRepo.UpdateStatusTx(ctx, tx, req.ID, domain.StatusFilled, fillPrice, req.Quantity); err != nil {
-		return nil, fmt.Errorf("update order status: %w", err)
+	cashAmount := -cost
+	equityAmount := cost
+	if side == domain.SideSell {
+		cashAmount = cost
+		equityAmount = -cost
 	}
-
-	amountSign := -cost
-	if req.Side == domain.SideSell {
-		amountSign = cost
+	txn := domain.Transaction{PortfolioID: portfolioID, OrderID: &orderID, Memo: memo}
+	legs := []domain.Posting{
+		{Account: domain.AccountCash, Amount: cashAmount},
+		{Account: domain.EquityAccount(symbol), Amount: equityAmount},
 	}
-	entry := domain.LedgerEntry{
-		PortfolioID:  req.PortfolioID,
-		OrderID:      &req.ID,
-		EntryType:    entryType,
-		Amount:       amountSign,
-		BalanceAfter: newBalance,
+	return s.ledgerRepo.PostTx(ctx, tx, &txn, legs)
+}
+
+// persistTerminal writes a rejected order plus its idempotency record within
+// tx and commits, used by the reject-before-fill branches above.
+func (s *OrderService) persistTerminal(ctx context.Context, tx *sqlx.Tx, req *domain.Order, idempotencyKey, requestHash string) (*domain.Order, error) {
+	if err := s.orderRepo.CreateTx(ctx, tx, req); err != nil {
+		return nil, err
 	}
-	if err := s.ledgerRepo.InsertTx(ctx, tx, &entry); err != nil {
-		return nil, fmt.Errorf("insert ledger entry: %w", err)
+	if err := s.idempotencyRepo.InsertTx(ctx, tx, req.PortfolioID, idempotencyKey, req.ID, requestHash); err != nil {
+		return nil, fmt.Errorf("insert idempotency record: %w", err)
 	}
-
 	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("commit transaction: %w", err)
+		return nil, err
 	}
+	return req, nil
+}
 
-	req.Status = domain.StatusFilled
-	req.FillPrice = &fillPrice
-	req.FilledQty = req.Quantity
-	now := time.Now()
-	req.FilledAt = &now
+// lookupIdempotent returns the previously-produced order for (portfolioID,
+// key) if one exists, or nil if this is a first submission.
+func (s *OrderService) lookupIdempotent(ctx context.Context, portfolioID uuid.UUID, key, requestHash string) (*domain.Order, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	return &req, nil
+	rec, err := s.idempotencyRepo.GetTx(ctx, tx, portfolioID, key)
+	if err != nil {
+		return nil, fmt.Errorf("lookup idempotency record: %w", err)
+	}
+	if rec == nil {
+		return nil, nil
+	}
+	if rec.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return s.orderRepo.GetByID(ctx, rec.OrderID)
 }
@@ -0,0 +1,15 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// PriceSource supplies the last traded price for a symbol. redis.PriceRepo
+// is the production implementation; conformance tests substitute an
+// in-memory fake so a scenario's price ticks replay deterministically
+// without a live Redis.
+type PriceSource interface {
+	GetLastPrice(ctx context.Context, symbol string) (*domain.PriceTick, error)
+}
@@ -0,0 +1,40 @@
+package execution
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// reservationRegistry tracks the reserve.Manager reservation held against
+// something that outlives a single request — a resting order or a pending
+// withdrawal — keyed by that thing's id, so a later event (a fill, a
+// cancel, a settlement) knows which reservation to release. It is
+// in-process only, like reserveMgr itself — a restart loses track of it
+// along with the matching engine's book, which OrderService.Rehydrate
+// already documents as a known gap.
+type reservationRegistry struct {
+	mu   sync.Mutex
+	byID map[uuid.UUID]uuid.UUID
+}
+
+func newReservationRegistry() *reservationRegistry {
+	return &reservationRegistry{byID: make(map[uuid.UUID]uuid.UUID)}
+}
+
+func (r *reservationRegistry) put(id, reservationID uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID[id] = reservationID
+}
+
+// take returns and forgets the reservation held for id, if any.
+func (r *reservationRegistry) take(id uuid.UUID) (uuid.UUID, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reservationID, ok := r.byID[id]
+	if ok {
+		delete(r.byID, id)
+	}
+	return reservationID, ok
+}
@@ -2,8 +2,10 @@ package execution
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/yourorg/paper-trader/internal/domain"
+	"github.com/yourorg/paper-trader/internal/instrument"
 )
 
 func validateOrderRequest(req *domain.Order) error {
@@ -19,9 +21,107 @@ func validateOrderRequest(req *domain.Order) error {
 		return fmt.Errorf("invalid order side: %s", req.Side)
 	}
 	switch req.OrderType {
-	case domain.TypeMarket, domain.TypeLimit, domain.TypeStop, domain.TypeStopLimit:
+	case domain.TypeMarket:
+	case domain.TypeLimit:
+		if req.LimitPrice == nil {
+			return fmt.Errorf("limit_price is required for a limit order")
+		}
+	case domain.TypeStop:
+		if req.StopPrice == nil {
+			return fmt.Errorf("stop_price is required for a stop order")
+		}
+	case domain.TypeStopLimit:
+		if req.StopPrice == nil || req.LimitPrice == nil {
+			return fmt.Errorf("stop_price and limit_price are both required for a stop-limit order")
+		}
 	default:
 		return fmt.Errorf("invalid order type: %s", req.OrderType)
 	}
 	return nil
 }
+
+// Machine-readable codes validateAgainstInstrument rejects with, so callers
+// (API clients, strategies) can branch on the failure instead of parsing
+// free text.
+const (
+	RejectReasonHalted      = "halted"
+	RejectReasonLotSize     = "lot_size"
+	RejectReasonTickSize    = "tick_size"
+	RejectReasonMinNotional = "min_notional"
+	RejectReasonMaxNotional = "max_notional"
+	RejectReasonOffSession  = "off_session"
+)
+
+// validateAgainstInstrument checks req against the contract-info record for
+// its symbol: tradability, tick size, lot size, and notional bounds. lastPrice
+// is used to estimate notional for market orders, which carry no LimitPrice.
+// It does not check session hours; callers decide what to do with a market
+// order submitted outside the symbol's trading session.
+func validateAgainstInstrument(req *domain.Order, sym *instrument.Symbol, lastPrice float64) (rejectReason string, err error) {
+	if !sym.Tradable {
+		return RejectReasonHalted, fmt.Errorf("symbol is not tradable: %s", req.Symbol)
+	}
+	if !sym.IsLotValid(req.Quantity) {
+		return RejectReasonLotSize, fmt.Errorf("quantity must be a multiple of the lot size (%v) for %s", sym.QtyLotSize, req.Symbol)
+	}
+	if req.LimitPrice != nil && !sym.IsTickValid(*req.LimitPrice) {
+		return RejectReasonTickSize, fmt.Errorf("limit price must be a multiple of the tick size (%v) for %s", sym.PriceTickSize, req.Symbol)
+	}
+
+	price := lastPrice
+	if req.LimitPrice != nil {
+		price = *req.LimitPrice
+	}
+	notional := price * req.Quantity
+	if notional < sym.MinNotional {
+		return RejectReasonMinNotional, fmt.Errorf("notional %.2f is below the minimum (%v) for %s", notional, sym.MinNotional, req.Symbol)
+	}
+	if !sym.IsNotionalValid(notional) {
+		return RejectReasonMaxNotional, fmt.Errorf("notional %.2f exceeds the maximum (%v) for %s", notional, sym.MaxNotional, req.Symbol)
+	}
+	return "", nil
+}
+
+// inSessionFor reports whether a market order may fill against the last
+// traded price right now, or whether it must wait for the open.
+func inSessionFor(sym *instrument.Symbol, now time.Time) bool {
+	return sym.InRTH(now)
+}
+
+// crossesImmediately reports whether req would fill right now against
+// lastPrice: always true for a market order (callers separately gate those
+// on session hours), true for a limit order marketable at lastPrice, and
+// always false for a stop or stop-limit order — those never fill at
+// submission and instead rest in the matching engine until a later tick
+// triggers them.
+func crossesImmediately(req *domain.Order, lastPrice float64) bool {
+	switch req.OrderType {
+	case domain.TypeMarket:
+		return true
+	case domain.TypeLimit:
+		if req.Side == domain.SideBuy {
+			return lastPrice <= *req.LimitPrice
+		}
+		return lastPrice >= *req.LimitPrice
+	default:
+		return false
+	}
+}
+
+// reservationPrice is the price used to size a resting buy order's cash
+// reservation: the limit price if the order has one, since a limit or
+// not-yet-triggered stop-limit order can never fill worse than that. A
+// plain stop order has no price ceiling until it triggers and becomes a
+// market order, so it reserves against its own stop price as a
+// best-effort estimate — still not a hard ceiling, since the trigger can
+// gap past it, which is why fillTriggeredStop re-checks buying power
+// before committing the fill rather than trusting this reservation alone.
+func reservationPrice(req *domain.Order, lastPrice float64) float64 {
+	if req.LimitPrice != nil {
+		return *req.LimitPrice
+	}
+	if req.StopPrice != nil {
+		return *req.StopPrice
+	}
+	return lastPrice
+}
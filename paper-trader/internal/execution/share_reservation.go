@@ -0,0 +1,62 @@
+package execution
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// shareReservations tracks the shares held against a resting sell order —
+// limit, stop, or stop-limit — keyed by order id, so a later sell
+// submission for the same (portfolio, symbol) sees shares already
+// committed to an earlier resting order and a fill or cancel knows how
+// many to release. Cash has reserveMgr for this; shares have no ledger
+// equivalent to check against until the sell actually fills, so this plays
+// the same role reserveMgr does for buys. It is in-process only, like
+// reserveMgr and reservationRegistry — lost on restart along with the
+// matching engine's book.
+type shareReservations struct {
+	mu      sync.Mutex
+	byOrder map[uuid.UUID]shareHold
+}
+
+type shareHold struct {
+	portfolioID uuid.UUID
+	symbol      string
+	qty         float64
+}
+
+func newShareReservations() *shareReservations {
+	return &shareReservations{byOrder: make(map[uuid.UUID]shareHold)}
+}
+
+func (s *shareReservations) put(orderID, portfolioID uuid.UUID, symbol string, qty float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byOrder[orderID] = shareHold{portfolioID: portfolioID, symbol: symbol, qty: qty}
+}
+
+// take returns and forgets the shares held for orderID, if any.
+func (s *shareReservations) take(orderID uuid.UUID) (shareHold, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.byOrder[orderID]
+	if ok {
+		delete(s.byOrder, orderID)
+	}
+	return h, ok
+}
+
+// reserved sums the shares of symbol currently held against portfolioID by
+// every resting sell order other than the one being checked.
+func (s *shareReservations) reserved(portfolioID uuid.UUID, symbol string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var total float64
+	for _, h := range s.byOrder {
+		if h.portfolioID == portfolioID && h.symbol == symbol {
+			total += h.qty
+		}
+	}
+	return total
+}
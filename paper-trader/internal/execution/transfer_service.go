@@ -0,0 +1,243 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/yourorg/paper-trader/internal/domain"
+	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
+	"github.com/yourorg/paper-trader/internal/reserve"
+)
+
+// DefaultSettlementDelay is how long a deposit or withdrawal sits pending
+// before the settlement worker applies it, simulating the latency of a
+// real ACH transfer.
+const DefaultSettlementDelay = 2 * time.Second
+
+// ErrInsufficientFunds is returned by Withdraw when amount exceeds the
+// portfolio's cash available to withdraw.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// TransferService submits deposits and withdrawals and settles them
+// asynchronously. A withdrawal holds its cash down via reserveMgr for as
+// long as it's pending, the same way OrderService holds cash against a
+// resting buy order.
+type TransferService struct {
+	db              *sqlx.DB
+	portfolioRepo   *pgRepo.PortfolioRepo
+	transferRepo    *pgRepo.TransferRepo
+	ledgerRepo      *pgRepo.LedgerRepo
+	reserveMgr      *reserve.Manager
+	settlementDelay time.Duration
+	clock           Clock
+	withdrawalResvs *reservationRegistry
+}
+
+func NewTransferService(
+	db *sqlx.DB,
+	portfolioRepo *pgRepo.PortfolioRepo,
+	transferRepo *pgRepo.TransferRepo,
+	ledgerRepo *pgRepo.LedgerRepo,
+	reserveMgr *reserve.Manager,
+	settlementDelay time.Duration,
+) *TransferService {
+	if settlementDelay <= 0 {
+		settlementDelay = DefaultSettlementDelay
+	}
+	return &TransferService{
+		db:              db,
+		portfolioRepo:   portfolioRepo,
+		transferRepo:    transferRepo,
+		ledgerRepo:      ledgerRepo,
+		reserveMgr:      reserveMgr,
+		settlementDelay: settlementDelay,
+		clock:           realClock{},
+		withdrawalResvs: newReservationRegistry(),
+	}
+}
+
+// Deposit creates a pending deposit of amount into portfolioID's cash
+// balance. Like a withdrawal, it only takes effect once the settlement
+// worker processes it.
+func (s *TransferService) Deposit(ctx context.Context, portfolioID uuid.UUID, amount float64, reference string) (*domain.Transfer, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+
+	transfer := &domain.Transfer{
+		PortfolioID: portfolioID,
+		Kind:        domain.TransferDeposit,
+		Amount:      amount,
+		Status:      domain.TransferPending,
+		Reference:   reference,
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.transferRepo.CreateTx(ctx, tx, transfer); err != nil {
+		return nil, fmt.Errorf("create transfer: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return transfer, nil
+}
+
+// Withdraw creates a pending withdrawal of amount from portfolioID's cash
+// balance, rejecting it with ErrInsufficientFunds if amount exceeds cash
+// available to withdraw (balance minus everything already reserved). The
+// amount is held down via reserveMgr until the settlement worker settles
+// or fails the transfer, so a second withdrawal can't double-spend it.
+func (s *TransferService) Withdraw(ctx context.Context, portfolioID uuid.UUID, amount float64, reference string) (*domain.Transfer, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+
+	unlock := s.reserveMgr.Lock(portfolioID)
+	defer unlock()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	portfolio, err := s.portfolioRepo.GetByIDForUpdateTx(ctx, tx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get portfolio: %w", err)
+	}
+
+	available := portfolio.CashBalance - s.reserveMgr.Reserved(portfolioID)
+	if amount > available {
+		return nil, ErrInsufficientFunds
+	}
+
+	transfer := &domain.Transfer{
+		PortfolioID: portfolioID,
+		Kind:        domain.TransferWithdrawal,
+		Amount:      amount,
+		Status:      domain.TransferPending,
+		Reference:   reference,
+	}
+	if err := s.transferRepo.CreateTx(ctx, tx, transfer); err != nil {
+		return nil, fmt.Errorf("create transfer: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	reservationID := s.reserveMgr.ReserveIndefinite(portfolioID, amount)
+	s.withdrawalResvs.put(transfer.ID, reservationID)
+
+	return transfer, nil
+}
+
+// RunSettlementWorker periodically settles every transfer that's been
+// pending for at least settlementDelay. It blocks until ctx is cancelled
+// and should be run in its own goroutine.
+func (s *TransferService) RunSettlementWorker(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.settleDue(ctx, logger)
+		}
+	}
+}
+
+func (s *TransferService) settleDue(ctx context.Context, logger *slog.Logger) {
+	cutoff := s.clock.Now().Add(-s.settlementDelay)
+	due, err := s.transferRepo.ListPendingBefore(ctx, cutoff)
+	if err != nil {
+		logger.Error("failed to list due transfers", "err", err)
+		return
+	}
+	for i := range due {
+		if err := s.settle(ctx, &due[i]); err != nil {
+			logger.Error("failed to settle transfer", "transfer_id", due[i].ID, "err", err)
+		}
+	}
+}
+
+// settle applies t's cash effect and posts the matching ledger transaction,
+// then marks it settled. A withdrawal's reservation is released up front,
+// before the cash is actually debited, so the two never double-count
+// against the same dollars. That release is also what makes a withdrawal
+// failable: if the portfolio's cash balance has genuinely fallen below the
+// withdrawal amount by the time settlement runs, debiting it would drive
+// the account negative, so settle fails the transfer instead of applying
+// it, the same way a triggered stop re-checks buying power before filling
+// rather than trusting a reservation made earlier to still hold.
+func (s *TransferService) settle(ctx context.Context, t *domain.Transfer) error {
+	unlock := s.reserveMgr.Lock(t.PortfolioID)
+	defer unlock()
+
+	if t.Kind == domain.TransferWithdrawal {
+		if reservationID, ok := s.withdrawalResvs.take(t.ID); ok {
+			s.reserveMgr.Release(t.PortfolioID, reservationID)
+		}
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	portfolio, err := s.portfolioRepo.GetByIDForUpdateTx(ctx, tx, t.PortfolioID)
+	if err != nil {
+		return fmt.Errorf("get portfolio: %w", err)
+	}
+
+	var newBalance float64
+	var memo string
+	cashAmount := t.Amount
+	externalAmount := -t.Amount
+	if t.Kind == domain.TransferDeposit {
+		newBalance = portfolio.CashBalance + t.Amount
+		memo = "deposit"
+	} else {
+		newBalance = portfolio.CashBalance - t.Amount
+		memo = "withdrawal"
+		cashAmount = -t.Amount
+		externalAmount = t.Amount
+	}
+
+	if t.Kind == domain.TransferWithdrawal && newBalance < 0 {
+		if err := s.transferRepo.FailTx(ctx, tx, t.ID); err != nil {
+			return fmt.Errorf("fail transfer: %w", err)
+		}
+		return tx.Commit()
+	}
+
+	if err := s.portfolioRepo.UpdateCashBalanceTx(ctx, tx, t.PortfolioID, newBalance); err != nil {
+		return fmt.Errorf("update cash balance: %w", err)
+	}
+
+	txn := domain.Transaction{PortfolioID: t.PortfolioID, TransferID: &t.ID, Memo: memo}
+	legs := []domain.Posting{
+		{Account: domain.AccountCash, Amount: cashAmount},
+		{Account: domain.AccountExternal, Amount: externalAmount},
+	}
+	if err := s.ledgerRepo.PostTx(ctx, tx, &txn, legs); err != nil {
+		return fmt.Errorf("post ledger transaction: %w", err)
+	}
+
+	if err := s.transferRepo.SettleTx(ctx, tx, t.ID); err != nil {
+		return fmt.Errorf("settle transfer: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -1,27 +1,41 @@
 package gateway
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"github.com/yourorg/paper-trader/internal/auth"
 	"github.com/yourorg/paper-trader/internal/domain"
 	"github.com/yourorg/paper-trader/internal/execution"
+	"github.com/yourorg/paper-trader/internal/instrument"
 	pgRepo "github.com/yourorg/paper-trader/internal/repository/postgres"
+	redisRepo "github.com/yourorg/paper-trader/internal/repository/redis"
+	"github.com/yourorg/paper-trader/internal/reserve"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Handlers struct {
-	userRepo      *pgRepo.UserRepo
-	portfolioRepo *pgRepo.PortfolioRepo
-	positionRepo  *pgRepo.PositionRepo
-	orderRepo     *pgRepo.OrderRepo
-	ledgerRepo    *pgRepo.LedgerRepo
-	orderSvc      *execution.OrderService
-	jwtSvc        *auth.JWTService
-	logger        *slog.Logger
+	userRepo         *pgRepo.UserRepo
+	portfolioRepo    *pgRepo.PortfolioRepo
+	positionRepo     *pgRepo.PositionRepo
+	orderRepo        *pgRepo.OrderRepo
+	ledgerRepo       *pgRepo.LedgerRepo
+	instrumentRepo   *pgRepo.InstrumentRepo
+	transferRepo     *pgRepo.TransferRepo
+	refreshTokenRepo *pgRepo.RefreshTokenRepo
+	orderSvc         *execution.OrderService
+	transferSvc      *execution.TransferService
+	reserveMgr       *reserve.Manager
+	jwtSvc           *auth.JWTService
+	revocationStore  *redisRepo.JTIRevocationStore
+	logger           *slog.Logger
 }
 
 func NewHandlers(
@@ -30,19 +44,31 @@ func NewHandlers(
 	positionRepo *pgRepo.PositionRepo,
 	orderRepo *pgRepo.OrderRepo,
 	ledgerRepo *pgRepo.LedgerRepo,
+	instrumentRepo *pgRepo.InstrumentRepo,
+	transferRepo *pgRepo.TransferRepo,
+	refreshTokenRepo *pgRepo.RefreshTokenRepo,
 	orderSvc *execution.OrderService,
+	transferSvc *execution.TransferService,
+	reserveMgr *reserve.Manager,
 	jwtSvc *auth.JWTService,
+	revocationStore *redisRepo.JTIRevocationStore,
 	logger *slog.Logger,
 ) *Handlers {
 	return &Handlers{
-		userRepo:      userRepo,
-		portfolioRepo: portfolioRepo,
-		positionRepo:  positionRepo,
-		orderRepo:     orderRepo,
-		ledgerRepo:    ledgerRepo,
-		orderSvc:      orderSvc,
-		jwtSvc:        jwtSvc,
-		logger:        logger,
+		userRepo:         userRepo,
+		portfolioRepo:    portfolioRepo,
+		positionRepo:     positionRepo,
+		orderRepo:        orderRepo,
+		ledgerRepo:       ledgerRepo,
+		instrumentRepo:   instrumentRepo,
+		transferRepo:     transferRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		orderSvc:         orderSvc,
+		transferSvc:      transferSvc,
+		reserveMgr:       reserveMgr,
+		jwtSvc:           jwtSvc,
+		revocationStore:  revocationStore,
+		logger:           logger,
 	}
 }
 
@@ -52,9 +78,30 @@ type registerRequest struct {
 }
 
 type authResponse struct {
-	Token     string            `json:"token"`
-	User      *domain.User      `json:"user"`
-	Portfolio *domain.Portfolio `json:"portfolio"`
+	Token        string            `json:"token"`
+	RefreshToken string            `json:"refresh_token"`
+	User         *domain.User      `json:"user"`
+	Portfolio    *domain.Portfolio `json:"portfolio"`
+}
+
+// issueTokenPair signs a fresh access token and mints a refresh token for
+// it, persisting only the refresh token's hash. Register, Login, and
+// Refresh all hand back the same pair shape; Refresh also needs the new
+// refresh token's row id to mark the old one replaced by it.
+func (h *Handlers) issueTokenPair(ctx context.Context, userID, portfolioID uuid.UUID, role domain.UserRole) (accessToken, refreshToken string, refreshTokenID uuid.UUID, err error) {
+	accessToken, err = h.jwtSvc.Sign(userID, portfolioID, role)
+	if err != nil {
+		return "", "", uuid.Nil, fmt.Errorf("sign access token: %w", err)
+	}
+	refreshToken, tokenHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return "", "", uuid.Nil, err
+	}
+	record, err := h.refreshTokenRepo.Create(ctx, userID, tokenHash, time.Now().Add(auth.RefreshTokenTTL))
+	if err != nil {
+		return "", "", uuid.Nil, fmt.Errorf("create refresh token: %w", err)
+	}
+	return accessToken, refreshToken, record.ID, nil
 }
 
 func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) {
@@ -89,12 +136,12 @@ func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to create portfolio")
 		return
 	}
-	token, err := h.jwtSvc.Sign(user.ID, portfolio.ID)
+	token, refreshToken, _, err := h.issueTokenPair(r.Context(), user.ID, portfolio.ID, user.Role)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to sign token")
 		return
 	}
-	writeJSON(w, http.StatusCreated, authResponse{Token: token, User: user, Portfolio: portfolio})
+	writeJSON(w, http.StatusCreated, authResponse{Token: token, RefreshToken: refreshToken, User: user, Portfolio: portfolio})
 }
 
 func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
@@ -117,12 +164,94 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to load portfolio")
 		return
 	}
-	token, err := h.jwtSvc.Sign(user.ID, portfolio.ID)
+	token, refreshToken, _, err := h.issueTokenPair(r.Context(), user.ID, portfolio.ID, user.Role)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to sign token")
 		return
 	}
-	writeJSON(w, http.StatusOK, authResponse{Token: token, User: user, Portfolio: portfolio})
+	writeJSON(w, http.StatusOK, authResponse{Token: token, RefreshToken: refreshToken, User: user, Portfolio: portfolio})
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh redeems a still-valid refresh token for a new access+refresh
+// pair, rotating the refresh token in the process: the old one is marked
+// replaced rather than deleted. If the presented token comes back already
+// revoked, it's being replayed — it was already rotated, or its family was
+// already revoked by an earlier replay — which is a sign it may have been stolen, so
+// the whole token family is revoked and the caller is sent back to log in
+// rather than handed a new pair.
+func (h *Handlers) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		writeError(w, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+	tokenHash := auth.HashRefreshToken(req.RefreshToken)
+	old, err := h.refreshTokenRepo.GetByHash(r.Context(), tokenHash)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to look up refresh token")
+		return
+	}
+	if old == nil {
+		writeError(w, http.StatusUnauthorized, "invalid or expired refresh token")
+		return
+	}
+	// Check reuse before expiry: a revoked row signals replay of a token
+	// whose family may still have a live descendant even if this one's own
+	// ExpiresAt has since passed, so that check must not short-circuit it.
+	if old.RevokedAt != nil {
+		if err := h.refreshTokenRepo.RevokeAllForUser(r.Context(), old.UserID); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to revoke token family")
+			return
+		}
+		writeError(w, http.StatusUnauthorized, "refresh token reuse detected, please log in again")
+		return
+	}
+	if old.ExpiresAt.Before(time.Now()) {
+		writeError(w, http.StatusUnauthorized, "invalid or expired refresh token")
+		return
+	}
+	user, err := h.userRepo.GetByID(r.Context(), old.UserID)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	portfolio, err := h.portfolioRepo.GetByUserID(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load portfolio")
+		return
+	}
+	token, refreshToken, newTokenID, err := h.issueTokenPair(r.Context(), user.ID, portfolio.ID, user.Role)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to sign token")
+		return
+	}
+	if err := h.refreshTokenRepo.MarkReplaced(r.Context(), old.ID, newTokenID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to rotate refresh token")
+		return
+	}
+	writeJSON(w, http.StatusOK, authResponse{Token: token, RefreshToken: refreshToken, User: user, Portfolio: portfolio})
+}
+
+// Logout revokes the caller's current access token by jti, so it stops
+// working immediately instead of riding out its remaining AccessTokenTTL.
+// The caller's refresh tokens are not revoked.
+func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if err := h.revocationStore.Revoke(r.Context(), claims.Jti, ttl); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to log out")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type portfolioResponse struct {
+	*domain.Portfolio
+	ReservedCash float64 `json:"reserved_cash"`
 }
 
 func (h *Handlers) GetPortfolio(w http.ResponseWriter, r *http.Request) {
@@ -132,7 +261,10 @@ func (h *Handlers) GetPortfolio(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "portfolio not found")
 		return
 	}
-	writeJSON(w, http.StatusOK, portfolio)
+	writeJSON(w, http.StatusOK, portfolioResponse{
+		Portfolio:    portfolio,
+		ReservedCash: h.reserveMgr.Reserved(portfolioID),
+	})
 }
 
 func (h *Handlers) GetPositions(w http.ResponseWriter, r *http.Request) {
@@ -155,22 +287,58 @@ func (h *Handlers) GetOrders(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, orders)
 }
 
-func (h *Handlers) GetLedger(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetLedgerTransactions(w http.ResponseWriter, r *http.Request) {
+	portfolioID := auth.PortfolioIDFromCtx(r.Context())
+	txns, err := h.ledgerRepo.GetTransactionsByPortfolioID(r.Context(), portfolioID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to fetch ledger transactions")
+		return
+	}
+	writeJSON(w, http.StatusOK, txns)
+}
+
+func (h *Handlers) GetLedgerAccount(w http.ResponseWriter, r *http.Request) {
+	portfolioID := auth.PortfolioIDFromCtx(r.Context())
+	account := domain.Account(r.PathValue("account"))
+	postings, err := h.ledgerRepo.GetAccountStatement(r.Context(), portfolioID, account)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to fetch account statement")
+		return
+	}
+	writeJSON(w, http.StatusOK, postings)
+}
+
+type trialBalanceResponse struct {
+	Balances map[domain.Account]float64 `json:"balances"`
+	Total    float64                    `json:"total"`
+	Balanced bool                       `json:"balanced"`
+}
+
+// GetTrialBalance asserts that the portfolio's postings net to zero. A
+// mismatch indicates a reconciliation bug: some transaction was written
+// with unbalanced legs, or bypassed PostTx entirely.
+func (h *Handlers) GetTrialBalance(w http.ResponseWriter, r *http.Request) {
 	portfolioID := auth.PortfolioIDFromCtx(r.Context())
-	entries, err := h.ledgerRepo.GetByPortfolioID(r.Context(), portfolioID)
+	balances, total, err := h.ledgerRepo.TrialBalance(r.Context(), portfolioID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to fetch ledger")
+		writeError(w, http.StatusInternalServerError, "failed to compute trial balance")
 		return
 	}
-	writeJSON(w, http.StatusOK, entries)
+	writeJSON(w, http.StatusOK, trialBalanceResponse{
+		Balances: balances,
+		Total:    total,
+		Balanced: math.Abs(total) < 1e-9,
+	})
 }
 
 type createOrderRequest struct {
-	Symbol     string           `json:"symbol"`
-	Side       domain.OrderSide `json:"side"`
-	OrderType  domain.OrderType `json:"order_type"`
-	Quantity   float64          `json:"quantity"`
-	LimitPrice *float64         `json:"limit_price,omitempty"`
+	Symbol        string           `json:"symbol"`
+	Side          domain.OrderSide `json:"side"`
+	OrderType     domain.OrderType `json:"order_type"`
+	Quantity      float64          `json:"quantity"`
+	LimitPrice    *float64         `json:"limit_price,omitempty"`
+	StopPrice     *float64         `json:"stop_price,omitempty"`
+	ClientOrderID string           `json:"client_order_id,omitempty"`
 }
 
 func (h *Handlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
@@ -180,6 +348,14 @@ func (h *Handlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		idempotencyKey = req.ClientOrderID
+	}
+	if idempotencyKey == "" {
+		writeError(w, http.StatusBadRequest, "Idempotency-Key header or client_order_id is required")
+		return
+	}
 	order := domain.Order{
 		PortfolioID: portfolioID,
 		Symbol:      req.Symbol,
@@ -187,9 +363,14 @@ func (h *Handlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		OrderType:   req.OrderType,
 		Quantity:    req.Quantity,
 		LimitPrice:  req.LimitPrice,
+		StopPrice:   req.StopPrice,
 	}
-	result, err := h.orderSvc.SubmitAndExecute(r.Context(), order)
+	result, err := h.orderSvc.SubmitAndExecute(r.Context(), idempotencyKey, order)
 	if err != nil {
+		if errors.Is(err, execution.ErrIdempotencyKeyReused) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -211,6 +392,111 @@ func (h *Handlers) GetOrder(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, order)
 }
 
+// CancelOrder cancels a still-resting limit, stop, or stop-limit order and
+// releases any cash held against it. Market orders and orders that have
+// already reached a terminal status return 409, since neither is resting
+// in the matching engine to cancel.
+func (h *Handlers) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	portfolioID := auth.PortfolioIDFromCtx(r.Context())
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+	if err := h.orderSvc.CancelOrder(r.Context(), portfolioID, id); err != nil {
+		if errors.Is(err, execution.ErrOrderNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type transferRequest struct {
+	Amount    float64 `json:"amount"`
+	Reference string  `json:"reference,omitempty"`
+}
+
+func (h *Handlers) CreateDeposit(w http.ResponseWriter, r *http.Request) {
+	portfolioID := auth.PortfolioIDFromCtx(r.Context())
+	var req transferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	transfer, err := h.transferSvc.Deposit(r.Context(), portfolioID, req.Amount, req.Reference)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, transfer)
+}
+
+func (h *Handlers) CreateWithdrawal(w http.ResponseWriter, r *http.Request) {
+	portfolioID := auth.PortfolioIDFromCtx(r.Context())
+	var req transferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	transfer, err := h.transferSvc.Withdraw(r.Context(), portfolioID, req.Amount, req.Reference)
+	if err != nil {
+		if errors.Is(err, execution.ErrInsufficientFunds) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, transfer)
+}
+
+func (h *Handlers) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	portfolioID := auth.PortfolioIDFromCtx(r.Context())
+	transfers, err := h.transferRepo.GetByPortfolioID(r.Context(), portfolioID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to fetch transfers")
+		return
+	}
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+// ReconcileLedger replays the portfolio's own postings and reports whether
+// they agree with their stored running balances, for an operator to check
+// after anything unusual (a failed migration, a manual data fix).
+func (h *Handlers) ReconcileLedger(w http.ResponseWriter, r *http.Request) {
+	portfolioID := auth.PortfolioIDFromCtx(r.Context())
+	if err := h.ledgerRepo.ReconcileLedger(r.Context(), portfolioID); err != nil {
+		if errors.Is(err, pgRepo.ErrLedgerCorrupt) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to reconcile ledger")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+func (h *Handlers) UpsertInstrument(w http.ResponseWriter, r *http.Request) {
+	var sym instrument.Symbol
+	if err := json.NewDecoder(r.Body).Decode(&sym); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if sym.Symbol == "" {
+		writeError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	if err := h.instrumentRepo.Upsert(r.Context(), &sym); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to upsert instrument")
+		return
+	}
+	writeJSON(w, http.StatusOK, sym)
+}
+
 func writeJSON(w http.ResponseWriter, code int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -6,10 +6,11 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/yourorg/paper-trader/internal/auth"
 )
 
-func NewRouter(h *Handlers, hub *Hub, jwtSvc *auth.JWTService) http.Handler {
+func NewRouter(h *Handlers, hub *Hub, jwtSvc *auth.JWTService, revocationStore auth.RevocationStore) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -26,18 +27,33 @@ func NewRouter(h *Handlers, hub *Hub, jwtSvc *auth.JWTService) http.Handler {
 
 	r.Post("/api/auth/register", h.Register)
 	r.Post("/api/auth/login", h.Login)
+	r.Post("/api/auth/refresh", h.Refresh)
 
 	r.Route("/api", func(r chi.Router) {
-		r.Use(auth.Middleware(jwtSvc))
+		r.Use(auth.Middleware(jwtSvc, revocationStore))
+		r.Post("/auth/logout", h.Logout)
 		r.Get("/portfolio", h.GetPortfolio)
 		r.Get("/positions", h.GetPositions)
 		r.Get("/orders", h.GetOrders)
 		r.Post("/orders", h.CreateOrder)
 		r.Get("/orders/{id}", h.GetOrder)
-		r.Get("/ledger", h.GetLedger)
+		r.Delete("/orders/{id}", h.CancelOrder)
+		r.Get("/ledger/transactions", h.GetLedgerTransactions)
+		r.Get("/ledger/accounts/{account}", h.GetLedgerAccount)
+		r.Get("/ledger/trial-balance", h.GetTrialBalance)
+		r.Get("/transfers", h.GetTransfers)
+		r.Post("/transfers/deposits", h.CreateDeposit)
+		r.Post("/transfers/withdrawals", h.CreateWithdrawal)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireAdmin)
+			r.Post("/admin/instruments", h.UpsertInstrument)
+			r.Post("/admin/reconcile", h.ReconcileLedger)
+		})
 	})
 
 	r.Get("/ws", ServeWS(hub, h.logger))
+	r.Handle("/metrics", promhttp.Handler())
 
 	return r
 }
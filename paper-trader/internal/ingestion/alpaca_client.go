@@ -8,54 +8,32 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/yourorg/paper-trader/internal/domain"
-	redisrepo "github.com/yourorg/paper-trader/internal/repository/redis"
 )
 
 const alpacaWSURL = "wss://stream.data.alpaca.markets/v2/iex"
 
-var defaultSymbols = []string{"AAPL", "TSLA", "MSFT", "NVDA", "SPY"}
-
+// AlpacaClient is a Provider backed by Alpaca's IEX trade feed.
 type AlpacaClient struct {
 	apiKey    string
 	apiSecret string
-	priceRepo *redisrepo.PriceRepo
 	logger    *slog.Logger
 }
 
-func NewAlpacaClient(key, secret string, repo *redisrepo.PriceRepo, logger *slog.Logger) *AlpacaClient {
+func NewAlpacaClient(key, secret string, logger *slog.Logger) *AlpacaClient {
 	return &AlpacaClient{
 		apiKey:    key,
 		apiSecret: secret,
-		priceRepo: repo,
 		logger:    logger,
 	}
 }
 
-func (c *AlpacaClient) Run(ctx context.Context) {
-	backoff := time.Second
-	maxBackoff := 60 * time.Second
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-		err := c.connect(ctx)
-		if err == nil {
-			backoff = time.Second
-			continue
-		}
-		c.logger.Error("alpaca ws disconnected", "err", err, "retrying_in", backoff)
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(backoff):
-		}
-		backoff *= 2
-		if backoff > maxBackoff {
-			backoff = maxBackoff
-		}
-	}
+func (c *AlpacaClient) Name() string { return "alpaca" }
+
+func (c *AlpacaClient) Run(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error {
+	providerRunner(ctx, c.Name(), c.logger, func(ctx context.Context) error {
+		return c.connect(ctx, symbols, out)
+	})
+	return nil
 }
 
 type alpacaMsg struct {
@@ -66,7 +44,12 @@ type alpacaMsg struct {
 	Ts string  `json:"t"`
 }
 
-func (c *AlpacaClient) connect(ctx context.Context) error {
+func (c *AlpacaClient) connect(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error {
+	if len(symbols) == 0 {
+		c.logger.Warn("no symbols configured for alpaca, skipping connect")
+		return nil
+	}
+
 	conn, _, err := websocket.DefaultDialer.DialContext(ctx, alpacaWSURL, nil)
 	if err != nil {
 		return err
@@ -105,7 +88,7 @@ func (c *AlpacaClient) connect(ctx context.Context) error {
 
 	subMsg, _ := json.Marshal(map[string]interface{}{
 		"action": "subscribe",
-		"trades": defaultSymbols,
+		"trades": symbols,
 	})
 	if err := conn.WriteMessage(websocket.TextMessage, subMsg); err != nil {
 		return err
@@ -115,7 +98,7 @@ func (c *AlpacaClient) connect(ctx context.Context) error {
 		return err
 	}
 
-	c.logger.Info("alpaca ws connected and subscribed")
+	c.logger.Info("alpaca ws connected and subscribed", "symbols", symbols)
 
 	for {
 		select {
@@ -150,8 +133,10 @@ func (c *AlpacaClient) connect(ctx context.Context) error {
 				Size:      msg.Sz,
 				Timestamp: ts,
 			}
-			if err := c.priceRepo.Publish(ctx, tick); err != nil {
-				c.logger.Error("failed to publish price tick", "err", err)
+			select {
+			case out <- tick:
+			case <-ctx.Done():
+				return nil
 			}
 		}
 	}
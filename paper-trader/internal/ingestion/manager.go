@@ -0,0 +1,93 @@
+package ingestion
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// PriceSink is the subset of redis.PriceRepo the Manager needs: somewhere
+// to publish every tick a Provider produces.
+type PriceSink interface {
+	Publish(ctx context.Context, tick domain.PriceTick) error
+}
+
+type providerBinding struct {
+	provider Provider
+	symbols  []string
+}
+
+// Manager runs a set of registered Providers concurrently and fans their
+// ticks into a single PriceSink. Symbols can be covered by more than one
+// provider (e.g. a crypto feed and an equities feed both configured), so
+// each symbol's first-registered provider is treated as primary; ticks for
+// that symbol from any other provider are dropped so the two feeds can't
+// stomp on each other's last price.
+type Manager struct {
+	bindings []providerBinding
+	sink     PriceSink
+	logger   *slog.Logger
+}
+
+func NewManager(sink PriceSink, logger *slog.Logger) *Manager {
+	return &Manager{sink: sink, logger: logger}
+}
+
+// Register adds a provider covering symbols. Registration order decides
+// primary-provider precedence for symbols shared across providers.
+func (m *Manager) Register(p Provider, symbols []string) {
+	m.bindings = append(m.bindings, providerBinding{provider: p, symbols: symbols})
+}
+
+// Run blocks until ctx is cancelled, running every registered provider and
+// publishing its ticks to the sink. Each provider's own Run already wraps
+// its session in providerRunner for reconnect/backoff, so Run calls it
+// directly rather than wrapping it a second time.
+func (m *Manager) Run(ctx context.Context) {
+	primary := m.primaryProviders()
+
+	var wg sync.WaitGroup
+	for _, b := range m.bindings {
+		b := b
+		ticks := make(chan domain.PriceTick, 256)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(ticks)
+			if err := b.provider.Run(ctx, b.symbols, ticks); err != nil {
+				m.logger.Error("market data provider exited", "provider", b.provider.Name(), "err", err)
+			}
+		}()
+
+		wg.Add(1)
+		go func(providerName string) {
+			defer wg.Done()
+			for tick := range ticks {
+				if primary[tick.Symbol] != providerName {
+					m.logger.Debug("dropping tick from non-primary provider",
+						"provider", providerName, "symbol", tick.Symbol)
+					continue
+				}
+				if err := m.sink.Publish(ctx, tick); err != nil {
+					m.logger.Error("failed to publish price tick", "provider", providerName, "err", err)
+				}
+			}
+		}(b.provider.Name())
+	}
+	wg.Wait()
+}
+
+func (m *Manager) primaryProviders() map[string]string {
+	primary := make(map[string]string)
+	for _, b := range m.bindings {
+		for _, symbol := range b.symbols {
+			if _, ok := primary[symbol]; !ok {
+				primary[symbol] = b.provider.Name()
+			}
+		}
+	}
+	return primary
+}
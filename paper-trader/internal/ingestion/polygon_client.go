@@ -0,0 +1,27 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// PolygonClient will stream trades from Polygon.io's websocket feed. Not
+// implemented yet — registering it with a Manager is a configuration error
+// until Run is filled in.
+type PolygonClient struct {
+	apiKey string
+	logger *slog.Logger
+}
+
+func NewPolygonClient(apiKey string, logger *slog.Logger) *PolygonClient {
+	return &PolygonClient{apiKey: apiKey, logger: logger}
+}
+
+func (c *PolygonClient) Name() string { return "polygon" }
+
+func (c *PolygonClient) Run(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error {
+	return fmt.Errorf("polygon provider not implemented yet")
+}
@@ -0,0 +1,19 @@
+// Package ingestion streams market-data ticks from one or more Providers
+// into the system's PriceRepo.
+package ingestion
+
+import (
+	"context"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// Provider is a market-data feed that streams trade ticks for symbols
+// until ctx is cancelled or it fails unrecoverably. Run should block for
+// the life of the feed, reconnecting as needed: the adapters here pass a
+// function covering one connected session to providerRunner, which supplies
+// the reconnect/backoff loop, and return from Run only once ctx is done.
+type Provider interface {
+	Name() string
+	Run(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error
+}
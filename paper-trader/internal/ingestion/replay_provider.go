@@ -0,0 +1,96 @@
+package ingestion
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// ReplayProvider replays a recorded tape of ticks from an NDJSON file, one
+// domain.PriceTick per line. It's meant for local development and tests,
+// where a live feed isn't available or wanted.
+type ReplayProvider struct {
+	path   string
+	speed  float64
+	logger *slog.Logger
+}
+
+// NewReplayProvider builds a provider that reads path, an NDJSON file of
+// domain.PriceTick records ordered by Timestamp. speed scales playback:
+// 1 reproduces the tape's original inter-tick gaps, 0 replays as fast as
+// possible.
+func NewReplayProvider(path string, speed float64, logger *slog.Logger) *ReplayProvider {
+	return &ReplayProvider{path: path, speed: speed, logger: logger}
+}
+
+func (p *ReplayProvider) Name() string { return "replay" }
+
+func (p *ReplayProvider) Run(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error {
+	providerRunner(ctx, p.Name(), p.logger, func(ctx context.Context) error {
+		return p.replay(ctx, symbols, out)
+	})
+	return nil
+}
+
+func (p *ReplayProvider) replay(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error {
+	wanted := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		wanted[s] = true
+	}
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return fmt.Errorf("open replay tape: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var last time.Time
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		var tick domain.PriceTick
+		if err := json.Unmarshal(scanner.Bytes(), &tick); err != nil {
+			p.logger.Warn("skipping malformed replay line", "err", err)
+			continue
+		}
+		if len(wanted) > 0 && !wanted[tick.Symbol] {
+			continue
+		}
+
+		if p.speed > 0 && !last.IsZero() {
+			gap := time.Duration(float64(tick.Timestamp.Sub(last)) / p.speed)
+			if gap > 0 {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(gap):
+				}
+			}
+		}
+		last = tick.Timestamp
+
+		select {
+		case out <- tick:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read replay tape: %w", err)
+	}
+	p.logger.Info("replay tape exhausted", "path", p.path)
+	return nil
+}
@@ -0,0 +1,62 @@
+package ingestion
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var disconnectsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "marketdata_provider_disconnects_total",
+	Help: "Count of market-data provider sessions that ended in an error, by provider name.",
+}, []string{"provider"})
+
+const (
+	initialBackoff = time.Second
+	maxBackoff     = 60 * time.Second
+)
+
+// providerRunner repeatedly calls session until ctx is cancelled, backing
+// off exponentially between attempts that return an error and resetting
+// the backoff after one that completes cleanly. Every adapter's Run method
+// delegates to this so reconnect behavior, logging, and disconnect metrics
+// stay identical across providers.
+func providerRunner(ctx context.Context, name string, logger *slog.Logger, session func(ctx context.Context) error) {
+	backoff := initialBackoff
+	attempt := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		attempt++
+		err := session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			backoff = initialBackoff
+			attempt = 0
+			continue
+		}
+
+		disconnectsTotal.WithLabelValues(name).Inc()
+		logger.Error("market data provider disconnected",
+			"provider", name, "attempt", attempt, "backoff_ms", backoff.Milliseconds(), "err", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
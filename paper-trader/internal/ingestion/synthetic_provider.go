@@ -0,0 +1,87 @@
+package ingestion
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// SyntheticProvider fabricates ticks for symbols by walking a geometric
+// Brownian motion around a starting price. It needs no network access, so
+// it suits local dev and demo environments that don't have Alpaca
+// credentials configured; the server uses it only when
+// MARKETDATA_PROVIDER=synthetic.
+type SyntheticProvider struct {
+	startPrice float64
+	volatility float64
+	interval   time.Duration
+	rng        *rand.Rand
+	logger     *slog.Logger
+}
+
+// NewSyntheticProvider builds a provider that ticks every interval,
+// moving each symbol's price by a GBM step with the given annualized
+// volatility around startPrice.
+func NewSyntheticProvider(startPrice, volatility float64, interval time.Duration, seed int64, logger *slog.Logger) *SyntheticProvider {
+	return &SyntheticProvider{
+		startPrice: startPrice,
+		volatility: volatility,
+		interval:   interval,
+		rng:        rand.New(rand.NewSource(seed)),
+		logger:     logger,
+	}
+}
+
+func (p *SyntheticProvider) Name() string { return "synthetic" }
+
+func (p *SyntheticProvider) Run(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error {
+	providerRunner(ctx, p.Name(), p.logger, func(ctx context.Context) error {
+		return p.walk(ctx, symbols, out)
+	})
+	return nil
+}
+
+func (p *SyntheticProvider) walk(ctx context.Context, symbols []string, out chan<- domain.PriceTick) error {
+	if len(symbols) == 0 {
+		p.logger.Warn("no symbols configured for synthetic provider, skipping")
+		return nil
+	}
+
+	prices := make(map[string]float64, len(symbols))
+	for _, s := range symbols {
+		prices[s] = p.startPrice
+	}
+
+	dt := p.interval.Seconds() / (252 * 6.5 * 3600)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			for _, symbol := range symbols {
+				drift := -0.5 * p.volatility * p.volatility * dt
+				shock := p.volatility * math.Sqrt(dt) * p.rng.NormFloat64()
+				prices[symbol] *= math.Exp(drift + shock)
+
+				tick := domain.PriceTick{
+					Symbol:    symbol,
+					Price:     prices[symbol],
+					Size:      100,
+					Timestamp: time.Now(),
+				}
+				select {
+				case out <- tick:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package instrument carries per-symbol contract metadata (tick size, lot
+// size, trading session) that execution needs to validate an order before it
+// ever reaches the ledger.
+package instrument
+
+import (
+	"time"
+)
+
+type AssetClass string
+
+const (
+	AssetClassEquity AssetClass = "equity"
+	AssetClassCrypto AssetClass = "crypto"
+)
+
+// MarketHours describes the regular (RTH) and extended (ETH) trading
+// session windows for a symbol, as wall-clock times in Timezone. Crypto
+// symbols trade around the clock and can leave these blank.
+type MarketHours struct {
+	Timezone string `db:"timezone" json:"timezone"`
+	RTHOpen  string `db:"rth_open" json:"rth_open"`   // "09:30"
+	RTHClose string `db:"rth_close" json:"rth_close"` // "16:00"
+	ETHOpen  string `db:"eth_open" json:"eth_open"`   // "04:00"
+	ETHClose string `db:"eth_close" json:"eth_close"` // "20:00"
+}
+
+// Symbol is the contract-info record for one tradable instrument.
+type Symbol struct {
+	Symbol        string      `db:"symbol"          json:"symbol"`
+	PriceTickSize float64     `db:"price_tick_size" json:"price_tick_size"`
+	QtyLotSize    float64     `db:"qty_lot_size"    json:"qty_lot_size"`
+	MinNotional   float64     `db:"min_notional"    json:"min_notional"`
+	MaxNotional   float64     `db:"max_notional"    json:"max_notional"`
+	MarketHours   MarketHours `json:"market_hours"`
+	Tradable      bool        `db:"tradable"         json:"tradable"`
+	AssetClass    AssetClass  `db:"asset_class"      json:"asset_class"`
+}
+
+// InRTH reports whether t falls inside the symbol's regular trading hours.
+// Symbols with no Timezone configured (crypto) are always considered in
+// session.
+func (s Symbol) InRTH(t time.Time) bool {
+	return s.inWindow(t, s.MarketHours.RTHOpen, s.MarketHours.RTHClose)
+}
+
+// InETH reports whether t falls inside the symbol's extended trading hours.
+func (s Symbol) InETH(t time.Time) bool {
+	return s.inWindow(t, s.MarketHours.ETHOpen, s.MarketHours.ETHClose)
+}
+
+func (s Symbol) inWindow(t time.Time, open, close string) bool {
+	if s.MarketHours.Timezone == "" || open == "" || close == "" {
+		return true
+	}
+	loc, err := time.LoadLocation(s.MarketHours.Timezone)
+	if err != nil {
+		return true
+	}
+	local := t.In(loc)
+	openAt, err := time.ParseInLocation("15:04", open, loc)
+	if err != nil {
+		return true
+	}
+	closeAt, err := time.ParseInLocation("15:04", close, loc)
+	if err != nil {
+		return true
+	}
+	mins := local.Hour()*60 + local.Minute()
+	openMins := openAt.Hour()*60 + openAt.Minute()
+	closeMins := closeAt.Hour()*60 + closeAt.Minute()
+	return mins >= openMins && mins < closeMins
+}
+
+// IsTickValid reports whether price is a multiple of the symbol's price
+// tick size (or the symbol has none configured).
+func (s Symbol) IsTickValid(price float64) bool {
+	return isMultiple(price, s.PriceTickSize)
+}
+
+// IsLotValid reports whether qty is a multiple of the symbol's quantity lot
+// size (or the symbol has none configured).
+func (s Symbol) IsLotValid(qty float64) bool {
+	return isMultiple(qty, s.QtyLotSize)
+}
+
+// IsNotionalValid reports whether notional falls within [MinNotional,
+// MaxNotional]. A zero MaxNotional is treated as "no upper bound".
+func (s Symbol) IsNotionalValid(notional float64) bool {
+	if notional < s.MinNotional {
+		return false
+	}
+	if s.MaxNotional > 0 && notional > s.MaxNotional {
+		return false
+	}
+	return true
+}
+
+func isMultiple(value, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	ratio := value / step
+	return ratio-roundNearest(ratio) < 1e-6 && roundNearest(ratio)-ratio < 1e-6
+}
+
+func roundNearest(v float64) float64 {
+	if v >= 0 {
+		return float64(int64(v + 0.5))
+	}
+	return float64(int64(v - 0.5))
+}
@@ -0,0 +1,181 @@
+// Package matching holds a per-symbol in-memory resting order book and the
+// triggered-when-crossed set that stop orders wait in. It has no database
+// or ledger dependencies of its own — execution.OrderService walks it on
+// every price tick and turns the Fills it returns into real trades through
+// the same transactional path a market order uses.
+package matching
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// Fill is one resting order being matched, fully or partially, at price.
+type Fill struct {
+	Order *domain.Order
+	Price float64
+	Qty   float64
+}
+
+// book is one symbol's resting limit orders: price level -> FIFO queue of
+// orders at that level, plus the level's prices kept sorted for walking in
+// price-time priority.
+type book struct {
+	mu        sync.Mutex
+	bidPrices []float64 // descending: best (highest) bid first
+	askPrices []float64 // ascending: best (lowest) ask first
+	bids      map[float64][]*domain.Order
+	asks      map[float64][]*domain.Order
+	byID      map[uuid.UUID]float64 // orderID -> its resting price, for cancel/lookup
+}
+
+func newBook() *book {
+	return &book{
+		bids: make(map[float64][]*domain.Order),
+		asks: make(map[float64][]*domain.Order),
+		byID: make(map[uuid.UUID]float64),
+	}
+}
+
+// add inserts a limit order into its side of the book at LimitPrice.
+func (b *book) add(order *domain.Order) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	price := *order.LimitPrice
+	b.byID[order.ID] = price
+	if order.Side == domain.SideBuy {
+		if _, ok := b.bids[price]; !ok {
+			b.bidPrices = append(b.bidPrices, price)
+			sort.Sort(sort.Reverse(sort.Float64Slice(b.bidPrices)))
+		}
+		b.bids[price] = append(b.bids[price], order)
+	} else {
+		if _, ok := b.asks[price]; !ok {
+			b.askPrices = append(b.askPrices, price)
+			sort.Float64s(b.askPrices)
+		}
+		b.asks[price] = append(b.asks[price], order)
+	}
+}
+
+// cancel removes orderID from the book if it's still resting, returning
+// whether it was found.
+func (b *book) cancel(orderID uuid.UUID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	price, ok := b.byID[orderID]
+	if !ok {
+		return false
+	}
+	delete(b.byID, orderID)
+
+	if queue, ok := b.bids[price]; ok {
+		if removed := removeFromQueue(queue, orderID); removed != nil {
+			if len(removed) == 0 {
+				delete(b.bids, price)
+				b.bidPrices = removePrice(b.bidPrices, price)
+			} else {
+				b.bids[price] = removed
+			}
+			return true
+		}
+	}
+	if queue, ok := b.asks[price]; ok {
+		if removed := removeFromQueue(queue, orderID); removed != nil {
+			if len(removed) == 0 {
+				delete(b.asks, price)
+				b.askPrices = removePrice(b.askPrices, price)
+			} else {
+				b.asks[price] = removed
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// match walks both sides of the book against tick, filling resting orders
+// crossed at their own limit price, best price and then oldest order
+// first. Each side can only absorb up to tick.Size of quantity — the
+// reported size of the trade that produced the tick — so an order resting
+// for more than that partially fills and stays in the book for the next
+// tick to finish.
+func (b *book) match(tick domain.PriceTick) []Fill {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var fills []Fill
+	fills = append(fills, b.matchSide(&b.bidPrices, b.bids, tick, func(level, last float64) bool { return last <= level })...)
+	fills = append(fills, b.matchSide(&b.askPrices, b.asks, tick, func(level, last float64) bool { return last >= level })...)
+	return fills
+}
+
+// matchSide drains every level that crosses (per shouldFill) into fills, at
+// that level's price, oldest order first, up to tick.Size of total
+// quantity. Orders that only partially fill stay at the front of their
+// level's queue.
+func (b *book) matchSide(prices *[]float64, levels map[float64][]*domain.Order, tick domain.PriceTick, shouldFill func(level, last float64) bool) []Fill {
+	var fills []Fill
+	var remainingPrices []float64
+	budget := tick.Size
+
+	for _, price := range *prices {
+		if budget <= 0 || !shouldFill(price, tick.Price) {
+			remainingPrices = append(remainingPrices, price)
+			continue
+		}
+
+		queue := levels[price]
+		var consumed int
+		for _, order := range queue {
+			if budget <= 0 {
+				break
+			}
+			remainingQty := order.Quantity - order.FilledQty
+			fillQty := remainingQty
+			if fillQty > budget {
+				fillQty = budget
+			}
+			fills = append(fills, Fill{Order: order, Price: price, Qty: fillQty})
+			budget -= fillQty
+			order.FilledQty += fillQty
+			if order.FilledQty >= order.Quantity {
+				consumed++
+				delete(b.byID, order.ID)
+			}
+		}
+
+		remaining := queue[consumed:]
+		if len(remaining) == 0 {
+			delete(levels, price)
+		} else {
+			levels[price] = remaining
+			remainingPrices = append(remainingPrices, price)
+		}
+	}
+	*prices = remainingPrices
+	return fills
+}
+
+func removeFromQueue(queue []*domain.Order, orderID uuid.UUID) []*domain.Order {
+	for i, o := range queue {
+		if o.ID == orderID {
+			return append(queue[:i], queue[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func removePrice(prices []float64, price float64) []float64 {
+	for i, p := range prices {
+		if p == price {
+			return append(prices[:i], prices[i+1:]...)
+		}
+	}
+	return prices
+}
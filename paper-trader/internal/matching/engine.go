@@ -0,0 +1,136 @@
+package matching
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+// Engine owns one resting order book per symbol plus the set of stop and
+// stop-limit orders still waiting to trigger. It is pure in-memory state;
+// nothing here talks to Postgres or the ledger.
+type Engine struct {
+	mu    sync.Mutex
+	books map[string]*book
+	stops map[string][]*domain.Order
+	byID  map[uuid.UUID]string // orderID -> symbol, so Cancel doesn't need the symbol
+}
+
+func NewEngine() *Engine {
+	return &Engine{
+		books: make(map[string]*book),
+		stops: make(map[string][]*domain.Order),
+		byID:  make(map[uuid.UUID]string),
+	}
+}
+
+func (e *Engine) bookFor(symbol string) *book {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b, ok := e.books[symbol]
+	if !ok {
+		b = newBook()
+		e.books[symbol] = b
+	}
+	return b
+}
+
+// RestLimit adds a limit order (or the resting leg a triggered stop-limit
+// converts into) to its symbol's book.
+func (e *Engine) RestLimit(order *domain.Order) {
+	e.mu.Lock()
+	e.byID[order.ID] = order.Symbol
+	e.mu.Unlock()
+	e.bookFor(order.Symbol).add(order)
+}
+
+// RestStop adds a stop or stop-limit order to its symbol's triggered-when-
+// crossed set.
+func (e *Engine) RestStop(order *domain.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.byID[order.ID] = order.Symbol
+	e.stops[order.Symbol] = append(e.stops[order.Symbol], order)
+}
+
+// Cancel removes orderID from wherever it's resting — the book or the stop
+// set — returning whether it was found.
+func (e *Engine) Cancel(orderID uuid.UUID) bool {
+	e.mu.Lock()
+	symbol, ok := e.byID[orderID]
+	if !ok {
+		e.mu.Unlock()
+		return false
+	}
+	delete(e.byID, orderID)
+	stops := e.stops[symbol]
+	e.mu.Unlock()
+
+	if e.bookFor(symbol).cancel(orderID) {
+		return true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, o := range stops {
+		if o.ID == orderID {
+			e.stops[symbol] = append(stops[:i], stops[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Triggered reports every stop/stop-limit order whose trigger condition
+// tick crosses, removing them from the stop set. Callers convert a plain
+// stop into an immediate market fill and a stop-limit into a resting limit
+// order via RestLimit.
+func (e *Engine) Triggered(tick domain.PriceTick) []*domain.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stops := e.stops[tick.Symbol]
+	var triggered, remaining []*domain.Order
+	for _, o := range stops {
+		if stopCrossed(o, tick.Price) {
+			triggered = append(triggered, o)
+			delete(e.byID, o.ID)
+		} else {
+			remaining = append(remaining, o)
+		}
+	}
+	e.stops[tick.Symbol] = remaining
+	return triggered
+}
+
+// stopCrossed reports whether tick crossed o's stop price: a buy stop
+// triggers on a breakout above it, a sell stop on a breakdown below it.
+func stopCrossed(o *domain.Order, tickPrice float64) bool {
+	if o.StopPrice == nil {
+		return false
+	}
+	if o.Side == domain.SideBuy {
+		return tickPrice >= *o.StopPrice
+	}
+	return tickPrice <= *o.StopPrice
+}
+
+// Match walks the resting book for tick.Symbol, returning every fill it
+// produces. Triggered stop/stop-limit orders are handled separately via
+// Triggered — call it first so a stop-limit that triggers this same tick
+// has a chance to be added to the book and matched in the same pass if the
+// caller re-invokes Match after RestLimit.
+func (e *Engine) Match(tick domain.PriceTick) []Fill {
+	fills := e.bookFor(tick.Symbol).match(tick)
+
+	e.mu.Lock()
+	for _, f := range fills {
+		if f.Order.FilledQty >= f.Order.Quantity {
+			delete(e.byID, f.Order.ID)
+		}
+	}
+	e.mu.Unlock()
+
+	return fills
+}
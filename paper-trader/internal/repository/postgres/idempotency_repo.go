@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RecordTTL bounds how long an idempotency record is honored for before the
+// sweeper reclaims it. Past this window a reused key is treated as a new
+// submission rather than a replay.
+const RecordTTL = 24 * time.Hour
+
+// IdempotencyRecord is the persisted row backing a replayed order
+// submission: the order it originally produced and a hash of the request
+// that produced it, so a replay with a different body can be rejected.
+type IdempotencyRecord struct {
+	PortfolioID uuid.UUID `db:"portfolio_id"`
+	Key         string    `db:"key"`
+	OrderID     uuid.UUID `db:"order_id"`
+	RequestHash string    `db:"request_hash"`
+}
+
+type IdempotencyRepo struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepo(db *sqlx.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+// GetTx looks up a prior submission for (portfolioID, key) within tx; it
+// takes no row locks of its own. Returns (nil, nil) if no record exists.
+func (r *IdempotencyRepo) GetTx(ctx context.Context, tx *sqlx.Tx, portfolioID uuid.UUID, key string) (*IdempotencyRecord, error) {
+	var rec IdempotencyRecord
+	err := tx.GetContext(ctx, &rec,
+		`SELECT portfolio_id, key, order_id, request_hash FROM order_idempotency WHERE portfolio_id = $1 AND key = $2`,
+		portfolioID, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// InsertTx records the order produced for (portfolioID, key) in the same
+// transaction that created the order, so the two can never diverge.
+func (r *IdempotencyRepo) InsertTx(ctx context.Context, tx *sqlx.Tx, portfolioID uuid.UUID, key string, orderID uuid.UUID, requestHash string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO order_idempotency (portfolio_id, key, order_id, request_hash)
+		VALUES ($1, $2, $3, $4)`,
+		portfolioID, key, orderID, requestHash)
+	return err
+}
+
+// DeleteExpired removes idempotency records older than RecordTTL, returning
+// how many rows were reclaimed. Meant to be called periodically by a
+// sweeper; expiring records doesn't disturb the orders they point to.
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM order_idempotency WHERE created_at < $1`, time.Now().Add(-RecordTTL))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
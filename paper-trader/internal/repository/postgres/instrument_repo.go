@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourorg/paper-trader/internal/instrument"
+)
+
+type InstrumentRepo struct {
+	db *sqlx.DB
+}
+
+func NewInstrumentRepo(db *sqlx.DB) *InstrumentRepo {
+	return &InstrumentRepo{db: db}
+}
+
+// instrumentColumns maps the market-hours columns onto Symbol's nested
+// MarketHours field and leaves out created_at/updated_at, which Symbol has
+// no field for.
+const instrumentColumns = `symbol, price_tick_size, qty_lot_size, min_notional, max_notional,
+	timezone AS "markethours.timezone", rth_open AS "markethours.rth_open",
+	rth_close AS "markethours.rth_close", eth_open AS "markethours.eth_open",
+	eth_close AS "markethours.eth_close", tradable, asset_class`
+
+func (r *InstrumentRepo) GetBySymbol(ctx context.Context, symbol string) (*instrument.Symbol, error) {
+	var sym instrument.Symbol
+	err := r.db.GetContext(ctx, &sym, `SELECT `+instrumentColumns+` FROM instruments WHERE symbol = $1`, symbol)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get instrument: %w", err)
+	}
+	return &sym, nil
+}
+
+func (r *InstrumentRepo) ListTradable(ctx context.Context) ([]instrument.Symbol, error) {
+	var symbols []instrument.Symbol
+	err := r.db.SelectContext(ctx, &symbols, `SELECT `+instrumentColumns+` FROM instruments WHERE tradable = TRUE ORDER BY symbol`)
+	if err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
+
+// Upsert creates or replaces the contract-info record for sym.Symbol.
+func (r *InstrumentRepo) Upsert(ctx context.Context, sym *instrument.Symbol) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO instruments (
+			symbol, price_tick_size, qty_lot_size, min_notional, max_notional,
+			timezone, rth_open, rth_close, eth_open, eth_close,
+			tradable, asset_class
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (symbol) DO UPDATE SET
+			price_tick_size = EXCLUDED.price_tick_size,
+			qty_lot_size    = EXCLUDED.qty_lot_size,
+			min_notional    = EXCLUDED.min_notional,
+			max_notional    = EXCLUDED.max_notional,
+			timezone        = EXCLUDED.timezone,
+			rth_open        = EXCLUDED.rth_open,
+			rth_close       = EXCLUDED.rth_close,
+			eth_open        = EXCLUDED.eth_open,
+			eth_close       = EXCLUDED.eth_close,
+			tradable        = EXCLUDED.tradable,
+			asset_class     = EXCLUDED.asset_class,
+			updated_at      = NOW()`,
+		sym.Symbol, sym.PriceTickSize, sym.QtyLotSize, sym.MinNotional, sym.MaxNotional,
+		sym.MarketHours.Timezone, sym.MarketHours.RTHOpen, sym.MarketHours.RTHClose,
+		sym.MarketHours.ETHOpen, sym.MarketHours.ETHClose,
+		sym.Tradable, sym.AssetClass)
+	return err
+}
@@ -2,12 +2,21 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/yourorg/paper-trader/internal/domain"
 )
 
+// ErrUnbalancedTransaction is returned by PostTx when the postings handed to
+// it don't sum to zero. The repo refuses to write a transaction that would
+// leave the books out of balance.
+var ErrUnbalancedTransaction = errors.New("unbalanced transaction: postings do not sum to zero")
+
 type LedgerRepo struct {
 	db *sqlx.DB
 }
@@ -16,22 +25,162 @@ func NewLedgerRepo(db *sqlx.DB) *LedgerRepo {
 	return &LedgerRepo{db: db}
 }
 
-func (r *LedgerRepo) InsertTx(ctx context.Context, tx *sqlx.Tx, entry *domain.LedgerEntry) error {
-	query := `
-		INSERT INTO ledger (portfolio_id, order_id, entry_type, amount, balance_after)
+// PostTx writes txn and its legs atomically within tx, stamping each leg
+// with the running balance of its account. It returns ErrUnbalancedTransaction
+// without writing anything if the legs don't sum to zero, so callers can
+// build a transaction speculatively and let PostTx be the integrity gate.
+func (r *LedgerRepo) PostTx(ctx context.Context, tx *sqlx.Tx, txn *domain.Transaction, legs []domain.Posting) error {
+	if len(legs) == 0 {
+		return fmt.Errorf("transaction has no postings")
+	}
+	var sum float64
+	for _, leg := range legs {
+		sum += leg.Amount
+	}
+	if math.Abs(sum) > 1e-9 {
+		return ErrUnbalancedTransaction
+	}
+
+	if txn.ID == uuid.Nil {
+		txn.ID = uuid.New()
+	}
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO transactions (id, portfolio_id, order_id, transfer_id, memo)
 		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at`
-	return tx.QueryRowContext(ctx, query,
-		entry.PortfolioID, entry.OrderID, entry.EntryType, entry.Amount, entry.BalanceAfter).
-		Scan(&entry.ID, &entry.CreatedAt)
+		RETURNING posted_at`,
+		txn.ID, txn.PortfolioID, txn.OrderID, txn.TransferID, txn.Memo).
+		Scan(&txn.PostedAt)
+	if err != nil {
+		return fmt.Errorf("insert transaction: %w", err)
+	}
+
+	for i := range legs {
+		leg := &legs[i]
+		leg.TransactionID = txn.ID
+		leg.PortfolioID = txn.PortfolioID
+
+		var prevBalance float64
+		err := tx.QueryRowContext(ctx, `
+			SELECT running_balance FROM postings
+			WHERE portfolio_id = $1 AND account = $2
+			ORDER BY id DESC LIMIT 1 FOR UPDATE`,
+			leg.PortfolioID, leg.Account).Scan(&prevBalance)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("lock account balance for %s: %w", leg.Account, err)
+		}
+		leg.RunningBalance = prevBalance + leg.Amount
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO postings (transaction_id, portfolio_id, account, amount, running_balance)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at`,
+			leg.TransactionID, leg.PortfolioID, leg.Account, leg.Amount, leg.RunningBalance).
+			Scan(&leg.ID, &leg.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert posting for %s: %w", leg.Account, err)
+		}
+	}
+	return nil
+}
+
+// TransactionWithPostings is the grouped view of a Transaction returned to
+// API callers: the transaction header plus every leg it posted.
+type TransactionWithPostings struct {
+	domain.Transaction
+	Postings []domain.Posting `json:"postings"`
 }
 
-func (r *LedgerRepo) GetByPortfolioID(ctx context.Context, portfolioID uuid.UUID) ([]domain.LedgerEntry, error) {
-	var entries []domain.LedgerEntry
-	err := r.db.SelectContext(ctx, &entries,
-		`SELECT * FROM ledger WHERE portfolio_id = $1 ORDER BY id DESC`, portfolioID)
+// GetTransactionsByPortfolioID returns every transaction for portfolioID,
+// most recent first, with its postings attached.
+func (r *LedgerRepo) GetTransactionsByPortfolioID(ctx context.Context, portfolioID uuid.UUID) ([]TransactionWithPostings, error) {
+	var txns []domain.Transaction
+	if err := r.db.SelectContext(ctx, &txns,
+		`SELECT * FROM transactions WHERE portfolio_id = $1 ORDER BY posted_at DESC`, portfolioID); err != nil {
+		return nil, fmt.Errorf("get transactions: %w", err)
+	}
+	if len(txns) == 0 {
+		return nil, nil
+	}
+
+	var postings []domain.Posting
+	if err := r.db.SelectContext(ctx, &postings,
+		`SELECT * FROM postings WHERE portfolio_id = $1 ORDER BY transaction_id, id`, portfolioID); err != nil {
+		return nil, fmt.Errorf("get postings: %w", err)
+	}
+	byTxn := make(map[uuid.UUID][]domain.Posting, len(txns))
+	for _, p := range postings {
+		byTxn[p.TransactionID] = append(byTxn[p.TransactionID], p)
+	}
+
+	result := make([]TransactionWithPostings, len(txns))
+	for i, t := range txns {
+		result[i] = TransactionWithPostings{Transaction: t, Postings: byTxn[t.ID]}
+	}
+	return result, nil
+}
+
+// GetAccountStatement returns every posting against account for
+// portfolioID, oldest first, each carrying its running balance.
+func (r *LedgerRepo) GetAccountStatement(ctx context.Context, portfolioID uuid.UUID, account domain.Account) ([]domain.Posting, error) {
+	var postings []domain.Posting
+	err := r.db.SelectContext(ctx, &postings,
+		`SELECT * FROM postings WHERE portfolio_id = $1 AND account = $2 ORDER BY id`,
+		portfolioID, account)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("get account statement: %w", err)
+	}
+	return postings, nil
+}
+
+// ErrLedgerCorrupt is returned by ReconcileLedger when a posting's stored
+// running_balance disagrees with the balance recomputed by replaying that
+// account's postings in order — a sign some posting was written outside
+// PostTx, or a row was edited or deleted after the fact.
+var ErrLedgerCorrupt = errors.New("ledger reconciliation failed: stored running balance disagrees with recomputation")
+
+// ReconcileLedger recomputes every account's running balance for
+// portfolioID by replaying its postings in id order and summing them,
+// returning ErrLedgerCorrupt at the first posting whose stored
+// running_balance disagrees with the recomputation.
+func (r *LedgerRepo) ReconcileLedger(ctx context.Context, portfolioID uuid.UUID) error {
+	var postings []domain.Posting
+	if err := r.db.SelectContext(ctx, &postings,
+		`SELECT * FROM postings WHERE portfolio_id = $1 ORDER BY account, id`, portfolioID); err != nil {
+		return fmt.Errorf("load postings: %w", err)
+	}
+
+	running := make(map[domain.Account]float64, len(postings))
+	for _, p := range postings {
+		running[p.Account] += p.Amount
+		if math.Abs(running[p.Account]-p.RunningBalance) > 1e-9 {
+			return fmt.Errorf("%w: account %s posting %d stored %.2f recomputed %.2f",
+				ErrLedgerCorrupt, p.Account, p.ID, p.RunningBalance, running[p.Account])
+		}
+	}
+	return nil
+}
+
+// TrialBalance sums every account's postings for portfolioID. The returned
+// total must be zero for the portfolio's books to be balanced; a nonzero
+// total means some past PostTx call wrote legs that didn't net to zero, or
+// a bug bypassed PostTx entirely.
+func (r *LedgerRepo) TrialBalance(ctx context.Context, portfolioID uuid.UUID) (balances map[domain.Account]float64, total float64, err error) {
+	var rows []struct {
+		Account domain.Account `db:"account"`
+		Balance float64        `db:"balance"`
+	}
+	err = r.db.SelectContext(ctx, &rows, `
+		SELECT account, SUM(amount) AS balance
+		FROM postings
+		WHERE portfolio_id = $1
+		GROUP BY account`, portfolioID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("compute trial balance: %w", err)
+	}
+	balances = make(map[domain.Account]float64, len(rows))
+	for _, row := range rows {
+		balances[row.Account] = row.Balance
+		total += row.Balance
 	}
-	return entries, nil
+	return balances, total, nil
 }
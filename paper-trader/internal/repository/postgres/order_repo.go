@@ -22,11 +22,11 @@ func (r *OrderRepo) CreateTx(ctx context.Context, tx *sqlx.Tx, o *domain.Order)
 		o.ID = uuid.New()
 	}
 	query := `
-		INSERT INTO orders (id, portfolio_id, symbol, side, order_type, quantity, limit_price, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO orders (id, portfolio_id, symbol, side, order_type, quantity, limit_price, stop_price, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING created_at, updated_at`
 	return tx.QueryRowContext(ctx, query,
-		o.ID, o.PortfolioID, o.Symbol, o.Side, o.OrderType, o.Quantity, o.LimitPrice, o.Status).
+		o.ID, o.PortfolioID, o.Symbol, o.Side, o.OrderType, o.Quantity, o.LimitPrice, o.StopPrice, o.Status).
 		Scan(&o.CreatedAt, &o.UpdatedAt)
 }
 
@@ -58,6 +58,49 @@ func (r *OrderRepo) GetByPortfolioID(ctx context.Context, portfolioID uuid.UUID)
 	return orders, nil
 }
 
+// ListResting returns every working non-market order (pending or partially
+// filled) so the in-memory matching book can be rehydrated on startup.
+func (r *OrderRepo) ListResting(ctx context.Context) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := r.db.SelectContext(ctx, &orders, `
+		SELECT * FROM orders
+		WHERE status IN ($1, $2) AND order_type != $3
+		ORDER BY created_at`,
+		domain.StatusPending, domain.StatusPartiallyFilled, domain.TypeMarket)
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ApplyFillTx records one match against a resting order: filled_qty grows
+// by qty and fill_price is overwritten with the match price, so it reads as
+// the most recent fill price rather than a volume-weighted average. status
+// becomes StatusFilled once filled_qty reaches quantity, else
+// StatusPartiallyFilled.
+func (r *OrderRepo) ApplyFillTx(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, price, qty float64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE orders
+		SET filled_qty = filled_qty + $1,
+		    fill_price = $2,
+		    status = CASE WHEN filled_qty + $1 >= quantity THEN $3 ELSE $4 END,
+		    filled_at = NOW(),
+		    updated_at = NOW()
+		WHERE id = $5`,
+		qty, price, domain.StatusFilled, domain.StatusPartiallyFilled, id)
+	return err
+}
+
+// CancelTx marks a still-working order cancelled. Callers are responsible
+// for removing it from the in-memory matching book first.
+func (r *OrderRepo) CancelTx(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE orders SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status IN ($3, $4)`,
+		domain.StatusCancelled, id, domain.StatusPending, domain.StatusPartiallyFilled)
+	return err
+}
+
 func (r *OrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
 	var o domain.Order
 	err := r.db.GetContext(ctx, &o, `SELECT * FROM orders WHERE id = $1`, id)
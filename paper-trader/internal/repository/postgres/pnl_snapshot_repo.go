@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PnLSnapshotRepo struct {
+	db *sqlx.DB
+}
+
+func NewPnLSnapshotRepo(db *sqlx.DB) *PnLSnapshotRepo {
+	return &PnLSnapshotRepo{db: db}
+}
+
+// Insert records one point of a backtest run's equity curve, keyed by
+// runID so multiple runs against the same portfolio can be queried apart.
+func (r *PnLSnapshotRepo) Insert(ctx context.Context, runID string, portfolioID uuid.UUID, tickAt time.Time, equity, realizedPnL, unrealizedPnL float64) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO pnl_snapshots (run_id, portfolio_id, tick_at, equity, realized_pnl, unrealized_pnl)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		runID, portfolioID, tickAt, equity, realizedPnL, unrealizedPnL)
+	return err
+}
@@ -56,6 +56,16 @@ func (r *PortfolioRepo) GetByIDForUpdateTx(ctx context.Context, tx *sqlx.Tx, id
 	return &p, nil
 }
 
+// ListIDs returns every portfolio id, for the startup ledger reconciliation
+// self-check to sweep across.
+func (r *PortfolioRepo) ListIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.SelectContext(ctx, &ids, `SELECT id FROM portfolios`); err != nil {
+		return nil, fmt.Errorf("list portfolio ids: %w", err)
+	}
+	return ids, nil
+}
+
 func (r *PortfolioRepo) UpdateCashBalanceTx(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, newBalance float64) error {
 	_, err := tx.ExecContext(ctx,
 		`UPDATE portfolios SET cash_balance = $1, updated_at = NOW() WHERE id = $2`,
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RefreshToken is the persisted row backing an issued refresh token. Only
+// TokenHash is ever stored — the opaque token itself is handed to the
+// client once and never written down.
+type RefreshToken struct {
+	ID         uuid.UUID  `db:"id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	TokenHash  string     `db:"token_hash"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	ReplacedBy *uuid.UUID `db:"replaced_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+type RefreshTokenRepo struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepo(db *sqlx.DB) *RefreshTokenRepo {
+	return &RefreshTokenRepo{db: db}
+}
+
+func (r *RefreshTokenRepo) Create(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) (*RefreshToken, error) {
+	rt := &RefreshToken{ID: uuid.New(), UserID: userID, TokenHash: tokenHash, ExpiresAt: expiresAt}
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`
+	if err := r.db.QueryRowContext(ctx, query, rt.ID, rt.UserID, rt.TokenHash, rt.ExpiresAt).Scan(&rt.CreatedAt); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// GetByHash looks up a refresh token by the hash of its opaque value,
+// revoked or not, so the caller can tell a replayed (already-rotated or
+// family-revoked) token apart from one that's simply garbage: garbage matches
+// no row at all, while a replay comes back with RevokedAt set. A row with
+// no matching hash still returns (nil, nil).
+func (r *RefreshTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	var rt RefreshToken
+	err := r.db.GetContext(ctx, &rt, `
+		SELECT * FROM refresh_tokens WHERE token_hash = $1`,
+		tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rt, nil
+}
+
+// MarkReplaced revokes id in favor of replacementID. Called after the
+// replacement row already exists, so a refresh token is never left without
+// a live successor between the two writes.
+func (r *RefreshTokenRepo) MarkReplaced(ctx context.Context, id, replacementID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $1
+		WHERE id = $2`,
+		replacementID, id)
+	return err
+}
+
+// Revoke marks id revoked with no replacement. No handler calls it; Logout
+// revokes only the access token.
+func (r *RefreshTokenRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// RevokeAllForUser revokes every refresh token for userID that isn't
+// already revoked. Called when a refresh token is redeemed a second time —
+// a sign its whole rotation chain may have been stolen — so the entire
+// family dies together rather than leaving a sibling token the attacker (or
+// the legitimate owner, now indistinguishable) could still redeem.
+func (r *RefreshTokenRepo) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID)
+	return err
+}
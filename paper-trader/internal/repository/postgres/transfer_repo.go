@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/yourorg/paper-trader/internal/domain"
+)
+
+type TransferRepo struct {
+	db *sqlx.DB
+}
+
+func NewTransferRepo(db *sqlx.DB) *TransferRepo {
+	return &TransferRepo{db: db}
+}
+
+func (r *TransferRepo) CreateTx(ctx context.Context, tx *sqlx.Tx, t *domain.Transfer) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO transfers (id, portfolio_id, kind, amount, status, reference)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at`
+	return tx.QueryRowContext(ctx, query,
+		t.ID, t.PortfolioID, t.Kind, t.Amount, t.Status, t.Reference).
+		Scan(&t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *TransferRepo) GetByPortfolioID(ctx context.Context, portfolioID uuid.UUID) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := r.db.SelectContext(ctx, &transfers,
+		`SELECT * FROM transfers WHERE portfolio_id = $1 ORDER BY created_at DESC`, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// ListPendingBefore returns every pending transfer created at or before
+// cutoff, oldest first, for the settlement worker to process.
+func (r *TransferRepo) ListPendingBefore(ctx context.Context, cutoff time.Time) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := r.db.SelectContext(ctx, &transfers, `
+		SELECT * FROM transfers
+		WHERE status = $1 AND created_at <= $2
+		ORDER BY created_at`,
+		domain.TransferPending, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("list pending transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// SettleTx marks a still-pending transfer settled. It only takes effect if
+// the transfer is still pending, so a transfer can't be settled twice.
+func (r *TransferRepo) SettleTx(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE transfers SET status = $1, settled_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND status = $3`,
+		domain.TransferSettled, id, domain.TransferPending)
+	return err
+}
+
+// FailTx marks a still-pending transfer failed, for a settlement attempt
+// that couldn't be applied (e.g. a withdrawal whose reservation was
+// released from under it).
+func (r *TransferRepo) FailTx(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE transfers SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3`,
+		domain.TransferFailed, id, domain.TransferPending)
+	return err
+}
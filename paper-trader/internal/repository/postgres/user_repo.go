@@ -19,11 +19,14 @@ func NewUserRepo(db *sqlx.DB) *UserRepo {
 
 func (r *UserRepo) Create(ctx context.Context, u *domain.User) error {
 	u.ID = uuid.New()
+	if u.Role == "" {
+		u.Role = domain.RoleTrader
+	}
 	query := `
-		INSERT INTO users (id, email, password_hash)
-		VALUES ($1, $2, $3)
+		INSERT INTO users (id, email, password_hash, role)
+		VALUES ($1, $2, $3, $4)
 		RETURNING created_at, updated_at`
-	return r.db.QueryRowContext(ctx, query, u.ID, u.Email, u.PasswordHash).
+	return r.db.QueryRowContext(ctx, query, u.ID, u.Email, u.PasswordHash, u.Role).
 		Scan(&u.CreatedAt, &u.UpdatedAt)
 }
 
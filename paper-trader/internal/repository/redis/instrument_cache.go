@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yourorg/paper-trader/internal/instrument"
+)
+
+// instrumentCacheTTL bounds how long a cached instrument record is served
+// before falling back to Postgres. Contract metadata changes rarely, so a
+// short TTL is about bounding staleness after an admin upsert, not hit rate.
+const instrumentCacheTTL = 30 * time.Second
+
+// instrumentSource is the subset of postgres.InstrumentRepo that
+// InstrumentCache wraps.
+type instrumentSource interface {
+	GetBySymbol(ctx context.Context, symbol string) (*instrument.Symbol, error)
+}
+
+// InstrumentCache sits in front of postgres.InstrumentRepo so every order
+// submission doesn't round-trip Postgres to re-read metadata that almost
+// never changes.
+type InstrumentCache struct {
+	client *redis.Client
+	source instrumentSource
+}
+
+func NewInstrumentCache(client *redis.Client, source instrumentSource) *InstrumentCache {
+	return &InstrumentCache{client: client, source: source}
+}
+
+func (c *InstrumentCache) GetBySymbol(ctx context.Context, symbol string) (*instrument.Symbol, error) {
+	key := "instrument:" + symbol
+	val, err := c.client.Get(ctx, key).Result()
+	if err == nil {
+		var sym instrument.Symbol
+		if err := json.Unmarshal([]byte(val), &sym); err != nil {
+			return nil, err
+		}
+		return &sym, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("redis get instrument: %w", err)
+	}
+
+	sym, err := c.source.GetBySymbol(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if sym == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(sym)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.client.Set(ctx, key, data, instrumentCacheTTL).Err(); err != nil {
+		return nil, fmt.Errorf("redis set instrument: %w", err)
+	}
+	return sym, nil
+}
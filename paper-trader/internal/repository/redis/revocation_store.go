@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// JTIRevocationStore marks access tokens revoked by jti. Each entry is set
+// with a TTL matching the token's remaining lifetime, so the set never
+// grows past the number of access tokens that are still otherwise valid.
+type JTIRevocationStore struct {
+	client *redis.Client
+}
+
+func NewJTIRevocationStore(client *redis.Client) *JTIRevocationStore {
+	return &JTIRevocationStore{client: client}
+}
+
+func (s *JTIRevocationStore) Revoke(ctx context.Context, jti uuid.UUID, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+	if err := s.client.Set(ctx, "revoked_jti:"+jti.String(), "1", ttl).Err(); err != nil {
+		return fmt.Errorf("redis revoke jti: %w", err)
+	}
+	return nil
+}
+
+func (s *JTIRevocationStore) IsRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	n, err := s.client.Exists(ctx, "revoked_jti:"+jti.String()).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis check revoked jti: %w", err)
+	}
+	return n > 0, nil
+}
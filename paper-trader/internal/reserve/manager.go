@@ -0,0 +1,160 @@
+// Package reserve implements an in-process cash reservation layer that sits
+// in front of the ledger. It lets OrderService hold down a slice of a
+// portfolio's cash balance for the lifetime of a single order submission, so
+// concurrent submissions against the same portfolio see consistent buying
+// power before any of them has committed a database transaction. A resting
+// limit, stop, or stop-limit order holds its reservation for as long as it
+// sits in the matching engine via ReserveIndefinite, rather than for a
+// single submission.
+package reserve
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTTL bounds how long a reservation can live before the sweeper
+// reclaims it, in case the holding request crashed before releasing it.
+const DefaultTTL = 30 * time.Second
+
+type reservation struct {
+	id          uuid.UUID
+	portfolioID uuid.UUID
+	amount      float64
+	expiresAt   time.Time
+}
+
+// Manager serializes order submissions per portfolio and tracks how much
+// cash is currently reserved against each one.
+type Manager struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*sync.Mutex
+	holds map[uuid.UUID]map[uuid.UUID]*reservation // portfolioID -> reservationID -> reservation
+	ttl   time.Duration
+}
+
+// NewManager returns a Manager whose reservations expire after ttl if never
+// released. A ttl of zero falls back to DefaultTTL.
+func NewManager(ttl time.Duration) *Manager {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Manager{
+		locks: make(map[uuid.UUID]*sync.Mutex),
+		holds: make(map[uuid.UUID]map[uuid.UUID]*reservation),
+		ttl:   ttl,
+	}
+}
+
+// Lock serializes callers for the given portfolio, returning an unlock func
+// that must be deferred. Concurrent order submissions for the same portfolio
+// block here until the previous one has reserved or released its cash.
+func (m *Manager) Lock(portfolioID uuid.UUID) func() {
+	m.mu.Lock()
+	l, ok := m.locks[portfolioID]
+	if !ok {
+		l = &sync.Mutex{}
+		m.locks[portfolioID] = l
+	}
+	m.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// Reserve holds down amount of cash against the portfolio and returns a
+// reservation id to release or consume later. Must be called while holding
+// the lock returned by Lock.
+func (m *Manager) Reserve(portfolioID uuid.UUID, amount float64) uuid.UUID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := uuid.New()
+	if m.holds[portfolioID] == nil {
+		m.holds[portfolioID] = make(map[uuid.UUID]*reservation)
+	}
+	m.holds[portfolioID][id] = &reservation{
+		id:          id,
+		portfolioID: portfolioID,
+		amount:      amount,
+		expiresAt:   time.Now().Add(m.ttl),
+	}
+	return id
+}
+
+// ReserveIndefinite holds down amount of cash with no expiry. It's for a
+// limit, stop, or stop-limit order resting in the matching engine, which can
+// sit for far longer than ttl — the sweeper never reclaims it, so the caller
+// must Release it explicitly once the order fills or is cancelled. Must be
+// called while holding the lock returned by Lock.
+func (m *Manager) ReserveIndefinite(portfolioID uuid.UUID, amount float64) uuid.UUID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := uuid.New()
+	if m.holds[portfolioID] == nil {
+		m.holds[portfolioID] = make(map[uuid.UUID]*reservation)
+	}
+	m.holds[portfolioID][id] = &reservation{
+		id:          id,
+		portfolioID: portfolioID,
+		amount:      amount,
+	}
+	return id
+}
+
+// Release drops a reservation without debiting the ledger itself: callers
+// release it when the order is rejected or cancelled, and the matching
+// engine's fill paths release a resting order's reservation as it fills.
+func (m *Manager) Release(portfolioID, reservationID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.holds[portfolioID], reservationID)
+}
+
+// Reserved returns the total cash currently reserved against a portfolio,
+// to be subtracted from cash_balance when computing buying power.
+func (m *Manager) Reserved(portfolioID uuid.UUID) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total float64
+	for _, r := range m.holds[portfolioID] {
+		total += r.amount
+	}
+	return total
+}
+
+// RunSweeper periodically expires stale reservations left behind by requests
+// that crashed between Reserve and Release/consume. It blocks until ctx is
+// cancelled and should be run in its own goroutine.
+func (m *Manager) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sweep()
+		}
+	}
+}
+
+func (m *Manager) sweep() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for portfolioID, reservations := range m.holds {
+		for id, r := range reservations {
+			if !r.expiresAt.IsZero() && now.After(r.expiresAt) {
+				delete(reservations, id)
+			}
+		}
+		if len(reservations) == 0 {
+			delete(m.holds, portfolioID)
+		}
+	}
+}